@@ -18,6 +18,7 @@ import (
 	"github.com/shaurya/gails/queue"
 	"github.com/shaurya/gails/queue/dashboard"
 	"github.com/shaurya/gails/websocket"
+	"gorm.io/gorm"
 )
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -33,6 +34,11 @@ type User struct {
 	Posts    []Post `gorm:"foreignKey:UserID"`
 }
 
+// BeforeSave never lets a plaintext password reach the database.
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	return auth.HashPasswordBeforeSave(&u.Password)
+}
+
 type Post struct {
 	orm.Model
 	Title    string `gorm:"not null" validate:"required"`
@@ -185,9 +191,6 @@ func main() {
 	// Register plugins
 	app.Register(&healthcheck.Plugin{})
 
-	// Initialize session store
-	auth.InitSession(app.Config.App.SecretKeyBase)
-
 	// Set up ORM-related items (these would work with a real database)
 	_ = orm.Query[User]
 	_ = orm.Query[Post]
@@ -217,6 +220,7 @@ func main() {
 			admin.NewResource[Post]().WithSearchFields("Title"),
 		},
 		Auth: admin.BasicAuth("admin", "password"),
+		DB:   app.DB,
 	})
 
 	// Mount job dashboard
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/shaurya/gails/framework"
+)
+
+// apiKeyPrefix makes Gails keys recognisable in logs and secret scanners.
+const apiKeyPrefix = "gails_"
+
+// GenerateAPIKey returns a new random API key and the hash to store for it.
+// Show the key to the caller once; persist only the hash.
+func GenerateAPIKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + hex.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the SHA-256 hex digest of key. API keys are long and
+// random, so a fast hash is enough and lets lookups use an indexed column.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareAPIKey reports whether key matches a stored hash in constant time.
+func CompareAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// APIKey authenticates service-to-service callers by a static API key sent as
+// "Authorization: ApiKey <key>" or "X-API-Key: <key>". lookup resolves a key to
+// a user ID; it should find the record by HashAPIKey(key) (or check candidates
+// with CompareAPIKey) so raw keys are never stored or compared directly.
+// Requests without a valid key get a 401. On success the user ID is available
+// via GetUserIDFromContext.
+//
+//	r.Namespace("/webhooks", func(r *framework.Router) {
+//		r.Use(auth.APIKey(func(key string) (uint, bool) {
+//			var k models.APIKey
+//			if err := db.Where("key_hash = ?", auth.HashAPIKey(key)).First(&k).Error; err != nil {
+//				return 0, false
+//			}
+//			return k.UserID, true
+//		}))
+//		...
+//	})
+func APIKey(lookup func(key string) (userID uint, ok bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				apiKeyUnauthorized(w)
+				return
+			}
+			userID, ok := lookup(key)
+			if !ok {
+				apiKeyUnauthorized(w)
+				return
+			}
+			ctx := framework.WithUserID(r.Context(), userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func apiKeyUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "ApiKey")
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write([]byte(`{"error":"Invalid or missing API key"}`))
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/shaurya/gails/framework"
+)
+
+// Authorizer decides whether user may perform action on resource. user is
+// ctx.CurrentUser(): the user from SetUserLoader, or the authenticated
+// user's ID (uint) without a loader. resource is whatever was passed to Can or
+// Authorize — a name like "posts", or a loaded record for ownership checks.
+// A returned error is reported as a server error, not a denial.
+type Authorizer interface {
+	Authorize(ctx *framework.Context, user any, action string, resource any) (bool, error)
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(ctx *framework.Context, user any, action string, resource any) (bool, error)
+
+// Authorize calls f.
+func (f AuthorizerFunc) Authorize(ctx *framework.Context, user any, action string, resource any) (bool, error) {
+	return f(ctx, user, action, resource)
+}
+
+var authorizer Authorizer
+
+// SetAuthorizer sets the policy used by Can and Authorize. Until one is set,
+// every check is denied.
+//
+//	auth.SetAuthorizer(auth.AuthorizerFunc(func(ctx *framework.Context, user any, action string, resource any) (bool, error) {
+//		u := user.(*User)
+//		if post, ok := resource.(*Post); ok && action == "update" {
+//			return post.AuthorID == u.ID || u.Admin, nil
+//		}
+//		return u.Admin, nil
+//	}))
+func SetAuthorizer(a Authorizer) {
+	authorizer = a
+}
+
+// Can wraps an Action so it only runs if the current user may perform
+// action on resource. Unauthenticated requests and denials get a 403.
+//
+//	r.POST("/posts", auth.Can("create", "posts", posts.Create))
+func Can(action, resource string, next framework.Action) framework.Action {
+	return func(ctx *framework.Context) error {
+		if err := Authorize(ctx, action, resource); err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+// Authorize checks the current user against the authorizer from inside an
+// action, typically once the record is loaded:
+//
+//	if err := auth.Authorize(ctx, "update", post); err != nil {
+//		return err
+//	}
+//
+// It returns a 403 error if there is no user or the check fails.
+func Authorize(ctx *framework.Context, action string, resource any) error {
+	if err := authenticate(ctx); err != nil {
+		return err
+	}
+	if authorizer == nil {
+		return ctx.Forbidden("Insufficient permissions")
+	}
+	allowed, err := authorizer.Authorize(ctx, ctx.CurrentUser(), action, resource)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ctx.Forbidden("Insufficient permissions")
+	}
+	return nil
+}
+
+// RequireAnyRole returns a wrapper that only runs the action if the user has
+// at least one of roles. Roles come from the current user's HasRole method
+// if it has one, otherwise from the session's "role" value (a string or a
+// []string).
+//
+//	r.GET("/reports", auth.RequireAnyRole("admin", "editor")(reports.Index))
+func RequireAnyRole(roles ...string) func(framework.Action) framework.Action {
+	return func(next framework.Action) framework.Action {
+		return func(ctx *framework.Context) error {
+			if err := authenticate(ctx); err != nil {
+				return err
+			}
+			if !slices.ContainsFunc(roles, func(role string) bool { return hasRole(ctx, role) }) {
+				return ctx.Forbidden("Insufficient permissions")
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// hasRole reports whether the current user has role.
+func hasRole(ctx *framework.Context, role string) bool {
+	if u, ok := ctx.CurrentUser().(interface{ HasRole(string) bool }); ok {
+		return u.HasRole(role)
+	}
+	sess := ctx.Session()
+	if sess == nil {
+		return false
+	}
+	switch v := sess.Values["role"].(type) {
+	case string:
+		return v == role
+	case []string:
+		return slices.Contains(v, role)
+	}
+	return false
+}
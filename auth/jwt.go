@@ -8,14 +8,11 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/shaurya/gails/framework"
 )
 
 var secretKey = []byte("change_me_in_production")
 
-type contextKey string
-
-const userIDContextKey contextKey = "gails_user_id"
-
 // InitJWT sets the JWT secret key.
 func InitJWT(secret string) {
 	if secret != "" {
@@ -77,7 +74,7 @@ func JWTMiddleware() func(http.Handler) http.Handler {
 			}
 
 			// Inject user ID into context
-			ctx := context.WithValue(r.Context(), userIDContextKey, userID)
+			ctx := framework.WithUserID(r.Context(), userID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -85,6 +82,5 @@ func JWTMiddleware() func(http.Handler) http.Handler {
 
 // GetUserIDFromContext extracts user ID from request context (set by JWTMiddleware).
 func GetUserIDFromContext(ctx context.Context) (uint, bool) {
-	id, ok := ctx.Value(userIDContextKey).(uint)
-	return id, ok
+	return framework.UserIDFromContext(ctx)
 }
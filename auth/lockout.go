@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaurya/gails/cache"
+	"github.com/shaurya/gails/framework"
+)
+
+// LockoutConfig configures login throttling.
+type LockoutConfig struct {
+	// MaxAttempts is how many failures lock a key out (default 5).
+	MaxAttempts int
+	// Cooldown is how long failures are remembered and a lockout lasts (default 15m).
+	Cooldown time.Duration
+}
+
+var (
+	lockoutCache  cache.Cache = cache.NewMemoryAdapter()
+	lockoutConfig             = LockoutConfig{MaxAttempts: 5, Cooldown: 15 * time.Minute}
+)
+
+// InitLockout configures login throttling. Pass the app cache so counters are
+// shared across instances; the default in-memory cache is per-process.
+func InitLockout(c cache.Cache, cfg LockoutConfig) {
+	if c != nil {
+		lockoutCache = c
+	}
+	if cfg.MaxAttempts > 0 {
+		lockoutConfig.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.Cooldown > 0 {
+		lockoutConfig.Cooldown = cfg.Cooldown
+	}
+}
+
+// LoginKeys returns the throttling keys for a login attempt: one for the
+// account and one for the client IP, so both targeted and spraying attacks
+// are throttled.
+//
+//	keys := auth.LoginKeys(ctx.Request, params.Email)
+//	if auth.IsLockedOut(keys...) {
+//		return ctx.Render("login.html", framework.H{"Error": "Too many attempts, try again later."})
+//	}
+//	if !user.Authenticate(params.Password) {
+//		auth.RecordFailedLogin(keys...)
+//		...
+//	}
+//	auth.ResetFailedLogins(keys[0])
+func LoginKeys(r *http.Request, account string) []string {
+	return []string{
+		"account:" + strings.ToLower(strings.TrimSpace(account)),
+		"ip:" + framework.ClientIP(r),
+	}
+}
+
+// RecordFailedLogin counts a failed attempt against each key. Failures expire
+// Cooldown after the first one in the window.
+func RecordFailedLogin(keys ...string) error {
+	for _, key := range keys {
+		if _, err := incrLockout(context.Background(), lockoutKey(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsLockedOut reports whether any key has reached MaxAttempts failures.
+func IsLockedOut(keys ...string) bool {
+	for _, key := range keys {
+		val, err := lockoutCache.Get(context.Background(), lockoutKey(key))
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.Atoi(val); err == nil && n >= lockoutConfig.MaxAttempts {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetFailedLogins clears the failure count for keys, e.g. after a successful login.
+func ResetFailedLogins(keys ...string) error {
+	for _, key := range keys {
+		if err := lockoutCache.Delete(context.Background(), lockoutKey(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lockoutKey(key string) string {
+	return "lockout:" + key
+}
+
+// incrLockout counts a failure against key. Caches without an atomic Incr
+// (see cache.Counter) get a read-then-write, which can lose a count under
+// concurrent failures.
+func incrLockout(ctx context.Context, key string) (int64, error) {
+	if c, ok := lockoutCache.(cache.Counter); ok {
+		return c.Incr(ctx, key, lockoutConfig.Cooldown)
+	}
+	var n int64
+	if val, err := lockoutCache.Get(ctx, key); err == nil {
+		n, _ = strconv.ParseInt(val, 10, 64)
+	}
+	n++
+	return n, lockoutCache.Set(ctx, key, n, lockoutConfig.Cooldown)
+}
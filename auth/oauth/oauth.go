@@ -0,0 +1,406 @@
+// Package oauth adds "Sign in with Google/GitHub/..." on top of the auth
+// package's session login, using the OAuth2 authorization code flow with
+// state, PKCE and (for OIDC providers) nonce checks.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shaurya/gails/auth"
+	"github.com/shaurya/gails/config"
+	"github.com/shaurya/gails/framework"
+	"golang.org/x/oauth2"
+)
+
+// Profile is the user information returned by a provider.
+type Profile struct {
+	Provider string
+	ID       string
+	Email    string
+	// EmailVerified reports whether the provider has verified that the user
+	// owns Email. Don't link a login to an existing account by email unless
+	// it is set.
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+	Raw           map[string]any
+}
+
+// Provider is an OAuth2 login provider.
+type Provider struct {
+	Name        string
+	Config      *oauth2.Config
+	UserInfoURL string
+	// OIDC providers get a nonce that is checked against the returned ID token.
+	OIDC bool
+	// ParseProfile maps the userinfo response to a Profile. Defaults to
+	// standard OIDC claims (sub, email, name, picture).
+	ParseProfile func(raw map[string]any) Profile
+	// FetchEmail returns the user's verified email. It is used when the
+	// userinfo response has no email, or doesn't say it is verified (GitHub's
+	// profile email is whatever the user typed in).
+	FetchEmail func(ctx context.Context, client *http.Client) (string, error)
+}
+
+// FindOrCreateFunc maps a provider profile to a local user, creating it if
+// needed, and returns the user's ID for the session.
+type FindOrCreateFunc func(ctx *framework.Context, profile Profile) (uint, error)
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]*Provider)
+)
+
+// Register adds a provider to the registry, replacing any with the same name.
+func Register(p *Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name] = p
+}
+
+// Get returns a registered provider.
+func Get(name string) (*Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Configure registers providers from the oauth section of the app config.
+// google and github have built-in endpoints; any other provider must set
+// auth_url, token_url and userinfo_url.
+func Configure(cfg map[string]config.OAuthProviderConfig) error {
+	for name, pc := range cfg {
+		var p *Provider
+		switch name {
+		case "google":
+			p = Google(pc.ClientID, pc.ClientSecret, pc.RedirectURL)
+		case "github":
+			p = GitHub(pc.ClientID, pc.ClientSecret, pc.RedirectURL)
+		default:
+			if pc.AuthURL == "" || pc.TokenURL == "" || pc.UserInfoURL == "" {
+				return fmt.Errorf("oauth: provider %q needs auth_url, token_url and userinfo_url", name)
+			}
+			p = &Provider{
+				Name: name,
+				Config: &oauth2.Config{
+					ClientID:     pc.ClientID,
+					ClientSecret: pc.ClientSecret,
+					RedirectURL:  pc.RedirectURL,
+					Endpoint:     oauth2.Endpoint{AuthURL: pc.AuthURL, TokenURL: pc.TokenURL},
+					Scopes:       []string{"openid", "email", "profile"},
+				},
+				UserInfoURL: pc.UserInfoURL,
+				OIDC:        true,
+			}
+		}
+		if len(pc.Scopes) > 0 {
+			p.Config.Scopes = pc.Scopes
+		}
+		Register(p)
+	}
+	return nil
+}
+
+// Google returns a Google (OIDC) provider.
+func Google(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name: "google",
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  "https://accounts.google.com/o/oauth2/auth",
+				TokenURL: "https://oauth2.googleapis.com/token",
+			},
+			Scopes: []string{"openid", "email", "profile"},
+		},
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		OIDC:        true,
+	}
+}
+
+// GitHub returns a GitHub provider.
+func GitHub(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name: "github",
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  "https://github.com/login/oauth/authorize",
+				TokenURL: "https://github.com/login/oauth/access_token",
+			},
+			Scopes: []string{"read:user", "user:email"},
+		},
+		UserInfoURL: "https://api.github.com/user",
+		ParseProfile: func(raw map[string]any) Profile {
+			return Profile{
+				ID:        stringClaim(raw, "id"),
+				Email:     stringClaim(raw, "email"),
+				Name:      firstNonEmpty(stringClaim(raw, "name"), stringClaim(raw, "login")),
+				AvatarURL: stringClaim(raw, "avatar_url"),
+			}
+		},
+		FetchEmail: githubPrimaryEmail,
+	}
+}
+
+// Routes mounts GET /auth/{provider} (redirect to the provider) and
+// GET /auth/{provider}/callback. After a successful callback the user is
+// logged in with auth.Login and redirected to successURL.
+//
+//	oauth.Configure(app.Config.OAuth)
+//	app.Routes(func(r *framework.Router) {
+//		oauth.Routes(r, findOrCreateUser, "/")
+//	})
+func Routes(r *framework.Router, findOrCreate FindOrCreateFunc, successURL string) {
+	r.GET("/auth/{provider}", Redirect)
+	r.GET("/auth/{provider}/callback", Callback(findOrCreate, successURL))
+}
+
+// Redirect starts the login flow for the {provider} route parameter.
+func Redirect(ctx *framework.Context) error {
+	p, ok := Get(ctx.Param("provider"))
+	if !ok {
+		return ctx.NotFound("Unknown login provider")
+	}
+
+	st := flowState{Provider: p.Name, State: randomToken(), Verifier: oauth2.GenerateVerifier()}
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(st.Verifier)}
+	if p.OIDC {
+		st.Nonce = randomToken()
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", st.Nonce))
+	}
+	if err := setFlowCookie(ctx.Response, st); err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Redirect(p.Config.AuthCodeURL(st.State, opts...))
+}
+
+// Callback completes the login flow: it checks state, exchanges the code,
+// fetches the profile and logs in the user returned by findOrCreate.
+func Callback(findOrCreate FindOrCreateFunc, successURL string) framework.Action {
+	return func(ctx *framework.Context) error {
+		p, ok := Get(ctx.Param("provider"))
+		if !ok {
+			return ctx.NotFound("Unknown login provider")
+		}
+
+		st, err := readFlowCookie(ctx.Request)
+		clearFlowCookie(ctx.Response)
+		if err != nil || st.Provider != p.Name ||
+			subtle.ConstantTimeCompare([]byte(st.State), []byte(ctx.Query("state"))) != 1 {
+			return ctx.Forbidden("Invalid OAuth state")
+		}
+		if e := ctx.Query("error"); e != "" {
+			return ctx.Forbidden("Login was not authorized: " + e)
+		}
+
+		token, err := p.Config.Exchange(ctx.Request.Context(), ctx.Query("code"), oauth2.VerifierOption(st.Verifier))
+		if err != nil {
+			return ctx.Forbidden("OAuth code exchange failed")
+		}
+		if p.OIDC && !nonceMatches(token, st.Nonce) {
+			return ctx.Forbidden("Invalid OIDC nonce")
+		}
+
+		profile, err := p.fetchProfile(ctx.Request.Context(), token)
+		if err != nil {
+			return ctx.InternalError(err)
+		}
+
+		userID, err := findOrCreate(ctx, profile)
+		if err != nil {
+			return err
+		}
+		if err := auth.Login(ctx.Response, ctx.Request, userID); err != nil {
+			return ctx.InternalError(err)
+		}
+		return ctx.Redirect(successURL)
+	}
+}
+
+// fetchProfile loads the provider's userinfo for token.
+func (p *Provider) fetchProfile(ctx context.Context, token *oauth2.Token) (Profile, error) {
+	client := p.Config.Client(ctx, token)
+	var raw map[string]any
+	if err := getJSON(client, p.UserInfoURL, &raw); err != nil {
+		return Profile{}, fmt.Errorf("oauth: fetching %s profile: %w", p.Name, err)
+	}
+
+	var profile Profile
+	if p.ParseProfile != nil {
+		profile = p.ParseProfile(raw)
+	} else {
+		profile = Profile{
+			ID:            stringClaim(raw, "sub"),
+			Email:         stringClaim(raw, "email"),
+			EmailVerified: boolClaim(raw, "email_verified"),
+			Name:          stringClaim(raw, "name"),
+			AvatarURL:     stringClaim(raw, "picture"),
+		}
+	}
+	profile.Provider = p.Name
+	profile.Raw = raw
+
+	if !profile.EmailVerified && p.FetchEmail != nil {
+		if email, err := p.FetchEmail(ctx, client); err == nil {
+			profile.Email, profile.EmailVerified = email, true
+		}
+	}
+	return profile, nil
+}
+
+// nonceMatches checks the ID token's nonce claim. The token came straight from
+// the token endpoint over TLS, so its signature need not be re-verified
+// (OpenID Connect Core §3.1.3.7).
+func nonceMatches(token *oauth2.Token, nonce string) bool {
+	rawID, _ := token.Extra("id_token").(string)
+	if rawID == "" {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(rawID, claims); err != nil {
+		return false
+	}
+	got, _ := claims["nonce"].(string)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) == 1
+}
+
+func githubPrimaryEmail(ctx context.Context, client *http.Client) (string, error) {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(client, "https://api.github.com/user/emails", &emails); err != nil {
+		return "", err
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+	return "", fmt.Errorf("oauth: no verified primary email")
+}
+
+// --- Flow state cookie ---
+
+const flowCookieName = "gails_oauth"
+
+// flowState is kept in a short-lived HttpOnly cookie between redirect and callback.
+type flowState struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Nonce    string `json:"n,omitempty"`
+	Verifier string `json:"v"`
+}
+
+func setFlowCookie(w http.ResponseWriter, st flowState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     flowCookieName,
+		Value:    hex.EncodeToString(data),
+		Path:     "/",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   os.Getenv("APP_ENV") == "production",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func readFlowCookie(r *http.Request) (flowState, error) {
+	var st flowState
+	c, err := r.Cookie(flowCookieName)
+	if err != nil {
+		return st, err
+	}
+	data, err := hex.DecodeString(c.Value)
+	if err != nil {
+		return st, err
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+func clearFlowCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: flowCookieName, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+}
+
+// --- Helpers ---
+
+func getJSON(client *http.Client, url string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func stringClaim(raw map[string]any, key string) string {
+	switch v := raw[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// boolClaim reads a boolean claim; some providers send it as a string.
+func boolClaim(raw map[string]any, key string) bool {
+	switch v := raw[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,15 +1,65 @@
 package auth
 
 import (
+	"fmt"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+// passwordCost is the bcrypt cost used by HashPassword.
+var passwordCost = 14
+
+// SetPasswordCost changes the bcrypt cost used for new hashes. Existing hashes
+// keep verifying, since bcrypt stores the cost in the hash itself.
+func SetPasswordCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("auth: bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	passwordCost = cost
+	return nil
+}
+
+// HashPassword hashes a plaintext password with bcrypt.
 func HashPassword(plain string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(plain), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(plain), passwordCost)
 	return string(bytes), err
 }
 
+// CheckPassword reports whether plain matches the bcrypt hash.
+//
+// Deprecated: Use VerifyPassword, which takes the hash first. CheckPassword's
+// plaintext-first order is easy to get backwards.
 func CheckPassword(plain, hash string) bool {
+	return VerifyPassword(hash, plain)
+}
+
+// VerifyPassword reports whether plain matches the bcrypt hash. The hash
+// comes first, matching bcrypt.CompareHashAndPassword, which compares the
+// digests in constant time.
+func VerifyPassword(hash, plain string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
 	return err == nil
 }
+
+// HashPasswordBeforeSave hashes *password in place unless it is empty or
+// already a bcrypt hash, so it is safe to call on every save:
+//
+//	func (u *User) BeforeSave(tx *gorm.DB) error {
+//		return auth.HashPasswordBeforeSave(&u.Password)
+//	}
+func HashPasswordBeforeSave(password *string) error {
+	if password == nil || *password == "" || isBcryptHash(*password) {
+		return nil
+	}
+	hash, err := HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	*password = hash
+	return nil
+}
+
+func isBcryptHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RememberToken is a persistent login token. The cookie carries
+// "selector:validator"; only a SHA-256 digest of the validator is stored, so a
+// leaked table can't be replayed as cookies.
+type RememberToken struct {
+	ID       uint   `gorm:"primarykey"`
+	UserID   uint   `gorm:"index;not null"`
+	Selector string `gorm:"uniqueIndex;size:32;not null"`
+	Digest   string `gorm:"size:64;not null"`
+	// PreviousDigest is the digest before the last rotation, still accepted
+	// until RotatedAt+rememberGrace for requests that raced the rotation.
+	PreviousDigest string `gorm:"size:64"`
+	RotatedAt      time.Time
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+const rememberCookieName = "gails_remember"
+
+// rememberGrace is how long a rotated-out validator keeps working. Parallel
+// requests (assets, XHR) sent with the same cookie would otherwise look like
+// a stolen token to all but the first.
+const rememberGrace = time.Minute
+
+var (
+	rememberDB  *gorm.DB
+	rememberTTL = 30 * 24 * time.Hour
+)
+
+// InitRememberMe enables remember-me tokens stored in db, valid for ttl
+// (default 30 days). It creates the remember_tokens table if needed.
+func InitRememberMe(db *gorm.DB, ttl time.Duration) error {
+	rememberDB = db
+	if ttl > 0 {
+		rememberTTL = ttl
+	}
+	return db.AutoMigrate(&RememberToken{})
+}
+
+// Remember issues a remember-me token for userID and sets a long-lived cookie.
+// Call it after Login when the user ticked "remember me".
+func Remember(w http.ResponseWriter, r *http.Request, userID uint) error {
+	if rememberDB == nil {
+		return nil
+	}
+	selector, validator := randomHex(16), randomHex(32)
+	token := RememberToken{
+		UserID:    userID,
+		Selector:  selector,
+		Digest:    digestHex(validator),
+		ExpiresAt: time.Now().Add(rememberTTL),
+	}
+	if err := rememberDB.WithContext(r.Context()).Create(&token).Error; err != nil {
+		return err
+	}
+	setRememberCookie(w, selector+":"+validator, int(rememberTTL.Seconds()))
+	return nil
+}
+
+// Forget revokes the request's remember-me token and clears its cookie.
+func Forget(w http.ResponseWriter, r *http.Request) error {
+	setRememberCookie(w, "", -1)
+	if rememberDB == nil {
+		return nil
+	}
+	selector, _, ok := readRememberCookie(r)
+	if !ok {
+		return nil
+	}
+	return rememberDB.WithContext(r.Context()).Where("selector = ?", selector).Delete(&RememberToken{}).Error
+}
+
+// ForgetAll revokes every remember-me token for a user ("log out everywhere").
+func ForgetAll(userID uint) error {
+	if rememberDB == nil {
+		return nil
+	}
+	return rememberDB.Where("user_id = ?", userID).Delete(&RememberToken{}).Error
+}
+
+// RememberMiddleware logs the user back in from a valid remember-me cookie when
+// the session has expired. Each use rotates the token; a known selector with a
+// wrong validator means the cookie was stolen, so all the user's tokens are
+// revoked. The validator it replaced stays valid for a minute, so concurrent
+// requests carrying the old cookie log in without rotating it again.
+func RememberMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rememberDB == nil || sessionUserID(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		selector, validator, ok := readRememberCookie(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		db := rememberDB.WithContext(r.Context())
+		var token RememberToken
+		if err := db.Where("selector = ?", selector).First(&token).Error; err != nil || time.Now().After(token.ExpiresAt) {
+			setRememberCookie(w, "", -1)
+			next.ServeHTTP(w, r)
+			return
+		}
+		digest := digestHex(validator)
+		current := subtle.ConstantTimeCompare([]byte(token.Digest), []byte(digest)) == 1
+		if !current && !(token.PreviousDigest != "" && time.Since(token.RotatedAt) < rememberGrace &&
+			subtle.ConstantTimeCompare([]byte(token.PreviousDigest), []byte(digest)) == 1) {
+			ForgetAll(token.UserID)
+			setRememberCookie(w, "", -1)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Login caches the session on r, so handlers see the user on this request.
+		if err := Login(w, r, token.UserID); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if current {
+			rotateRememberToken(w, db, token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rotateRememberToken replaces token's validator and sets the new cookie. The
+// update only applies if no concurrent request rotated it first; that
+// request's response carries the new cookie instead.
+func rotateRememberToken(w http.ResponseWriter, db *gorm.DB, token RememberToken) {
+	validator := randomHex(32)
+	now := time.Now()
+	res := db.Model(&RememberToken{}).
+		Where("id = ? AND digest = ?", token.ID, token.Digest).
+		Updates(map[string]any{
+			"digest":          digestHex(validator),
+			"previous_digest": token.Digest,
+			"rotated_at":      now,
+			"expires_at":      now.Add(rememberTTL),
+		})
+	if res.Error == nil && res.RowsAffected == 1 {
+		setRememberCookie(w, token.Selector+":"+validator, int(rememberTTL.Seconds()))
+	}
+}
+
+func sessionUserID(r *http.Request) any {
+	return session(r).Values["user_id"]
+}
+
+func readRememberCookie(r *http.Request) (selector, validator string, ok bool) {
+	c, err := r.Cookie(rememberCookieName)
+	if err != nil {
+		return "", "", false
+	}
+	selector, validator, ok = strings.Cut(c.Value, ":")
+	return selector, validator, ok && selector != "" && validator != ""
+}
+
+func setRememberCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rememberCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   os.Getenv("APP_ENV") == "production",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func digestHex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,20 +1,27 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/sessions"
+	"github.com/shaurya/gails/config"
 	"github.com/shaurya/gails/framework"
+	"gorm.io/gorm"
 )
 
-var store sessions.Store
-
-// InitSession initializes the session store.
+// InitSession initializes the session store with a cookie store.
+//
+// Deprecated: App.Boot builds the session store from the sessions config,
+// which auth shares; call framework.InitSessionStore to set it up manually.
 func InitSession(secret string) {
-	if secret == "" {
-		secret = "change_me_in_production"
-	}
-	store = sessions.NewCookieStore([]byte(secret))
+	framework.InitSessionStore(&config.Config{App: config.AppConfig{SecretKeyBase: secret}})
+}
+
+// session returns the app session for r.
+func session(r *http.Request) *sessions.Session {
+	sess, _ := framework.SessionStore().Get(r, framework.SessionName)
+	return sess
 }
 
 // SessionMiddleware loads the user session on each request.
@@ -25,59 +32,111 @@ func SessionMiddleware(next http.Handler) http.Handler {
 }
 
 // Login logs in a user by setting their ID in the session.
-// It regenerates the session ID to prevent session fixation.
+// It regenerates the session ID to prevent session fixation: with a
+// server-side store such as Redis, the pre-login session is deleted and its
+// values move to a new ID.
 func Login(w http.ResponseWriter, r *http.Request, userID uint) error {
-	if store == nil {
-		InitSession("")
+	session := session(r)
+	if !session.IsNew {
+		session.Options.MaxAge = -1
+		if err := session.Save(r, w); err != nil {
+			return err
+		}
+		session.ID = ""
+		session.IsNew = true
 	}
-	session, _ := store.Get(r, "gails_session")
-	// Regenerate session ID to prevent fixation
 	session.Options.MaxAge = 86400
 	session.Values["user_id"] = userID
 	return session.Save(r, w)
 }
 
-// Logout logs out the current user by clearing the session.
+// Logout logs out the current user by clearing the session and revoking
+// the request's remember-me token, if any.
 func Logout(w http.ResponseWriter, r *http.Request) error {
-	if store == nil {
-		return nil
+	if rememberDB != nil {
+		if err := Forget(w, r); err != nil {
+			return err
+		}
 	}
-	session, _ := store.Get(r, "gails_session")
+	session := session(r)
 	session.Values["user_id"] = nil
 	session.Options.MaxAge = -1
 	return session.Save(r, w)
 }
 
-// Required wraps an Action to require authentication.
+// userLoader loads the user for an authenticated ID; see SetUserLoader.
+var userLoader func(ctx *framework.Context, id uint) (any, error)
+
+// SetUserLoader sets how Required, Can and RequireAnyRole load the
+// authenticated user for ctx.CurrentUser. Without a loader the current user
+// is just the ID. Return gorm.ErrRecordNotFound (or nil, nil) for users that
+// no longer exist; the request is then treated as unauthenticated.
+//
+//	auth.SetUserLoader(func(ctx *framework.Context, id uint) (any, error) {
+//		var user User
+//		return &user, app.DB.WithContext(ctx.Context()).First(&user, id).Error
+//	})
+func SetUserLoader(fn func(ctx *framework.Context, id uint) (any, error)) {
+	userLoader = fn
+}
+
+// UserID returns the authenticated user's ID. The session's user_id comes
+// first, so a logged-in browser is always its session user; otherwise it is
+// the ID set by JWTMiddleware or APIKey.
+func UserID(ctx *framework.Context) (uint, bool) {
+	if sess := ctx.Session(); sess != nil {
+		switch id := sess.Values["user_id"].(type) {
+		case uint:
+			return id, true
+		case int:
+			return uint(id), true
+		case int64:
+			return uint(id), true
+		case uint64:
+			return uint(id), true
+		}
+	}
+	return framework.UserIDFromContext(ctx.Request.Context())
+}
+
+// Required wraps an Action to require authentication, by session or by
+// token (see UserID). The user is set as ctx.CurrentUser.
 func Required(next framework.Action) framework.Action {
 	return func(ctx *framework.Context) error {
-		sess := ctx.Session()
-		if sess == nil {
-			return ctx.Forbidden("Authentication required")
-		}
-		uid := sess.Values["user_id"]
-		if uid == nil {
-			return ctx.Forbidden("Authentication required")
+		if err := authenticate(ctx); err != nil {
+			return err
 		}
 		return next(ctx)
 	}
 }
 
-// RequireRole wraps an Action to require a specific role.
-func RequireRole(role string, next framework.Action) framework.Action {
-	return func(ctx *framework.Context) error {
-		sess := ctx.Session()
-		if sess == nil {
-			return ctx.Forbidden("Authentication required")
-		}
-		uid := sess.Values["user_id"]
-		if uid == nil {
+// authenticate sets ctx.CurrentUser from UserID and the user loader, unless
+// it is already set. It returns a 403 error if no one is logged in.
+func authenticate(ctx *framework.Context) error {
+	if ctx.CurrentUser() != nil {
+		return nil
+	}
+	id, ok := UserID(ctx)
+	if !ok {
+		return ctx.Forbidden("Authentication required")
+	}
+	var user any = id
+	if userLoader != nil {
+		u, err := userLoader(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && u == nil) {
 			return ctx.Forbidden("Authentication required")
 		}
-		userRole, _ := sess.Values["role"].(string)
-		if userRole != role {
-			return ctx.Forbidden("Insufficient permissions")
+		if err != nil {
+			return err
 		}
-		return next(ctx)
+		user = u
 	}
+	ctx.SetCurrentUser(user)
+	return nil
+}
+
+// RequireRole wraps an Action to require a specific role. See RequireAnyRole
+// for where roles come from.
+func RequireRole(role string, next framework.Action) framework.Action {
+	return RequireAnyRole(role)(next)
 }
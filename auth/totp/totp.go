@@ -0,0 +1,192 @@
+// Package totp implements time-based one-time passwords (RFC 6238) and
+// single-use recovery codes for two-factor authentication.
+//
+// Enrolment:
+//
+//	key, _ := totp.Generate("MyApp", user.Email)
+//	// store key.Secret on the user (encrypted at rest), render key.URL as a QR code
+//
+// Login, after the password has been checked:
+//
+//	auth.BeginTwoFactor(ctx.Response, ctx.Request, user.ID)
+//	return ctx.Redirect("/login/2fa")
+//
+//	// POST /login/2fa; wrong codes are rate limited by the auth lockout
+//	err := auth.VerifyTwoFactor(ctx.Response, ctx.Request, func(userID uint) bool {
+//		return totp.Validate(code, loadUser(userID).TOTPSecret)
+//	})
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period is the lifetime of a code in seconds.
+	Period = 30
+	// Digits is the length of a code.
+	Digits = 6
+	// Skew is how many periods either side of now Validate accepts, to allow
+	// for clock drift and slow typists.
+	Skew = 1
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key is a newly generated TOTP secret and its provisioning URL.
+type Key struct {
+	// Secret is the base32 shared secret to store for the user.
+	Secret string
+	// URL is the otpauth:// URL authenticator apps scan as a QR code.
+	URL string
+}
+
+// Generate creates a random 160-bit secret for account, labelled with issuer
+// in authenticator apps.
+func Generate(issuer, account string) (*Key, error) {
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := b32.EncodeToString(raw)
+	return &Key{Secret: secret, URL: URL(secret, issuer, account)}, nil
+}
+
+// URL returns the otpauth:// provisioning URL for secret.
+func URL(secret, issuer, account string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(Period))
+	// Some authenticator apps show "+" literally, so encode spaces as %20.
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+// Code returns the code for secret at time t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(t.Unix()/Period)), nil
+}
+
+// Validate reports whether code is valid for secret now, allowing Skew
+// periods of drift.
+func Validate(code, secret string) bool {
+	return ValidateAt(code, secret, time.Now(), Skew)
+}
+
+// ValidateAt reports whether code is valid for secret at time t, accepting
+// codes up to window periods before or after t.
+//
+// A code stays valid for its whole window, so apps that need strict one-time
+// use should remember the last accepted code per user and reject repeats.
+func ValidateAt(code, secret string, t time.Time, window int) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != Digits {
+		return false
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return false
+	}
+	counter := t.Unix() / Period
+	valid := false
+	for i := -window; i <= window; i++ {
+		if counter+int64(i) < 0 {
+			continue
+		}
+		// Check every step rather than returning early to keep timing flat.
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(counter+int64(i)))), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// hotp computes an RFC 4226 code for counter.
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, bin%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return b32.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// --- Recovery codes ---
+
+// GenerateRecoveryCodes returns n one-time recovery codes to show the user and
+// the hashes to store in their place.
+func GenerateRecoveryCodes(n int) (codes, hashes []string, err error) {
+	for range n {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(raw)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the storable digest of a recovery code. Input is
+// normalised, so "ABCDE-12345", "abcde12345" and "abcde 12345" hash the same.
+func HashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseRecoveryCode checks code against the stored hashes. On success it returns
+// the hashes with the used one removed; persist them so the code can't be
+// used again.
+func UseRecoveryCode(code string, hashes []string) (remaining []string, ok bool) {
+	digest := []byte(HashRecoveryCode(code))
+	match := -1
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare(digest, []byte(h)) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return hashes, false
+	}
+	remaining = make([]string, 0, len(hashes)-1)
+	remaining = append(remaining, hashes[:match]...)
+	return append(remaining, hashes[match+1:]...), true
+}
@@ -0,0 +1,99 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 4226 Appendix D and RFC 6238 Appendix B.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTPRFC4226Vectors(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), uint64(counter)); got != code {
+			t.Errorf("hotp(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 lists 8-digit codes; with 6 digits they keep their last six.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.code {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidateAtSkewWindow(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		at     time.Time
+		window int
+		want   bool
+	}{
+		{"same period", now, 0, true},
+		{"next period, no skew", now.Add(Period * time.Second), 0, false},
+		{"next period", now.Add(Period * time.Second), 1, true},
+		{"previous period", now.Add(-Period * time.Second), 1, true},
+		{"two periods later", now.Add(2 * Period * time.Second), 1, false},
+		{"two periods earlier", now.Add(-2 * Period * time.Second), 1, false},
+	}
+	for _, tt := range tests {
+		if got := ValidateAt(code, rfcSecret, tt.at, tt.window); got != tt.want {
+			t.Errorf("%s: ValidateAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !ValidateAt(code[:3]+" "+code[3:], rfcSecret, now, 0) {
+		t.Error("ValidateAt rejected a code with a space")
+	}
+	if ValidateAt("12345", rfcSecret, now, 1) {
+		t.Error("ValidateAt accepted a short code")
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 3 || len(hashes) != 3 {
+		t.Fatalf("got %d codes and %d hashes, want 3 each", len(codes), len(hashes))
+	}
+
+	remaining, ok := UseRecoveryCode(codes[1], hashes)
+	if !ok {
+		t.Fatal("UseRecoveryCode rejected a fresh code")
+	}
+	if len(remaining) != 2 || remaining[0] != hashes[0] || remaining[1] != hashes[2] {
+		t.Errorf("remaining = %v, want the other two hashes", remaining)
+	}
+	if _, ok := UseRecoveryCode(codes[1], remaining); ok {
+		t.Error("UseRecoveryCode accepted a code twice")
+	}
+	if _, ok := UseRecoveryCode(" "+codes[0][:5]+codes[0][6:]+" ", remaining); !ok {
+		t.Error("UseRecoveryCode rejected a code typed without its dash")
+	}
+}
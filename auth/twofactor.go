@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrNoPendingTwoFactor is returned by CompleteTwoFactor when no password
+	// step is pending or it has expired.
+	ErrNoPendingTwoFactor = errors.New("auth: no pending two-factor login")
+	// ErrInvalidTwoFactor is returned by VerifyTwoFactor for a wrong code.
+	ErrInvalidTwoFactor = errors.New("auth: invalid two-factor code")
+	// ErrTwoFactorLockedOut is returned once a user has entered too many wrong
+	// codes; the pending login is cancelled and the password step must be
+	// repeated after the lockout Cooldown.
+	ErrTwoFactorLockedOut = errors.New("auth: too many two-factor attempts")
+)
+
+// twoFactorTTL bounds how long a user may take to enter their second factor.
+const twoFactorTTL = 5 * time.Minute
+
+// BeginTwoFactor records that userID passed the password step and must now
+// provide a second factor (see the auth/totp package). The user is not logged
+// in until CompleteTwoFactor is called.
+func BeginTwoFactor(w http.ResponseWriter, r *http.Request, userID uint) error {
+	session := session(r)
+	session.Values["user_id"] = nil
+	session.Values["2fa_user_id"] = userID
+	session.Values["2fa_expires"] = time.Now().Add(twoFactorTTL).Unix()
+	return session.Save(r, w)
+}
+
+// PendingTwoFactor returns the user awaiting a second factor, if the password
+// step was completed within the last few minutes.
+func PendingTwoFactor(r *http.Request) (uint, bool) {
+	session := session(r)
+	userID, ok := session.Values["2fa_user_id"].(uint)
+	if !ok {
+		return 0, false
+	}
+	expires, _ := session.Values["2fa_expires"].(int64)
+	if time.Now().Unix() > expires {
+		return 0, false
+	}
+	return userID, true
+}
+
+// VerifyTwoFactor checks the pending user's second factor with verify and
+// logs them in if it passes. Wrong codes count against the user in the login
+// lockout (see InitLockout), so a 6-digit code can't be brute-forced: after
+// MaxAttempts failures the pending login is cancelled and
+// ErrTwoFactorLockedOut is returned until Cooldown has passed.
+//
+//	err := auth.VerifyTwoFactor(ctx.Response, ctx.Request, func(userID uint) bool {
+//		user := loadUser(userID)
+//		return totp.Validate(params.Code, user.TOTPSecret)
+//	})
+func VerifyTwoFactor(w http.ResponseWriter, r *http.Request, verify func(userID uint) bool) error {
+	userID, ok := PendingTwoFactor(r)
+	if !ok {
+		return ErrNoPendingTwoFactor
+	}
+	key := twoFactorKey(userID)
+	if IsLockedOut(key) {
+		return cancelTwoFactor(w, r)
+	}
+	if !verify(userID) {
+		if err := RecordFailedLogin(key); err != nil {
+			return err
+		}
+		if IsLockedOut(key) {
+			return cancelTwoFactor(w, r)
+		}
+		return ErrInvalidTwoFactor
+	}
+	ResetFailedLogins(key)
+	return CompleteTwoFactor(w, r)
+}
+
+// CompleteTwoFactor logs in the pending user once their code has been
+// verified. Prefer VerifyTwoFactor, which also limits attempts; this refuses
+// users it has locked out.
+func CompleteTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := PendingTwoFactor(r)
+	if !ok {
+		return ErrNoPendingTwoFactor
+	}
+	if IsLockedOut(twoFactorKey(userID)) {
+		return cancelTwoFactor(w, r)
+	}
+	session := session(r)
+	delete(session.Values, "2fa_user_id")
+	delete(session.Values, "2fa_expires")
+	return Login(w, r, userID)
+}
+
+// cancelTwoFactor drops the pending login and returns ErrTwoFactorLockedOut.
+func cancelTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	session := session(r)
+	delete(session.Values, "2fa_user_id")
+	delete(session.Values, "2fa_expires")
+	if err := session.Save(r, w); err != nil {
+		return err
+	}
+	return ErrTwoFactorLockedOut
+}
+
+// twoFactorKey is the lockout key for userID's second-factor attempts.
+func twoFactorKey(userID uint) string {
+	return "2fa:" + strconv.FormatUint(uint64(userID), 10)
+}
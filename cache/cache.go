@@ -2,14 +2,30 @@ package cache
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"gorm.io/gorm/schema"
 )
 
-// CacheKeyer is implemented by models to generate cache keys.
+// CacheKeyer is implemented by models that choose their own cache key (see
+// ModelKey).
 type CacheKeyer interface {
 	CacheKey() string
 }
 
+// Counter is implemented by caches that can increment a value atomically;
+// MemoryAdapter and RedisAdapter both do.
+type Counter interface {
+	// Incr atomically increments a counter, creating it with the given TTL
+	// (a fixed window) if it doesn't exist, and returns the new value.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
 // Cache defines the full caching interface for Gails.
 type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
@@ -26,6 +42,54 @@ type Cache interface {
 	Publish(ctx context.Context, channel string, message any) error
 	Subscribe(ctx context.Context, channel string) (<-chan string, error)
 	// Model-level cache helpers
-	SetModel(ctx context.Context, model CacheKeyer, data any, ttl time.Duration) error
-	GetModel(ctx context.Context, model CacheKeyer) (string, error)
+	SetModel(ctx context.Context, model any, data any, ttl time.Duration) error
+	GetModel(ctx context.Context, model any) (string, error)
+}
+
+// ModelKey returns the cache key SetModel and GetModel use for model. A model
+// that implements CacheKeyer chooses its own key. Otherwise the key is built
+// from the model's table name, ID and UpdatedAt, so it changes whenever the
+// record is saved:
+//
+//	posts/42-1700000000123456789
+//
+// Unsaved records (zero ID) get "posts/new"; models without UpdatedAt get
+// "posts/42".
+func ModelKey(model any) string {
+	if k, ok := model.(CacheKeyer); ok {
+		return k.CacheKey()
+	}
+	v := reflect.ValueOf(model)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Sprintf("%T", model)
+	}
+	key := tableName(model, v.Type())
+	id := v.FieldByName("ID")
+	if !id.IsValid() {
+		return key
+	}
+	if id.IsZero() {
+		return key + "/new"
+	}
+	key += "/" + fmt.Sprint(id.Interface())
+	if f := v.FieldByName("UpdatedAt"); f.IsValid() {
+		if t, ok := f.Interface().(time.Time); ok && !t.IsZero() {
+			key += "-" + strconv.FormatInt(t.UnixNano(), 10)
+		}
+	}
+	return key
+}
+
+var schemas sync.Map
+
+// tableName returns the table GORM stores model in, honouring a TableName
+// method, or the lowercased type name if GORM can't parse model.
+func tableName(model any, t reflect.Type) string {
+	if s, err := schema.Parse(model, &schemas, schema.NamingStrategy{}); err == nil {
+		return s.Table
+	}
+	return strings.ToLower(t.Name())
 }
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type keyPost struct {
+	ID        uint
+	UpdatedAt time.Time
+}
+
+type keyPerson struct{ ID uint }
+
+func (keyPerson) TableName() string { return "people" }
+
+type keyCustom struct{ ID uint }
+
+func (keyCustom) CacheKey() string { return "custom" }
+
+func TestModelKey(t *testing.T) {
+	updated := time.Unix(1700000000, 123)
+	tests := []struct {
+		name  string
+		model any
+		want  string
+	}{
+		{"saved", &keyPost{ID: 42, UpdatedAt: updated}, "key_posts/42-1700000000000000123"},
+		{"unsaved", &keyPost{}, "key_posts/new"},
+		{"table name method", &keyPerson{ID: 7}, "people/7"},
+		{"own cache key", &keyCustom{ID: 1}, "custom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ModelKey(tt.model); got != tt.want {
+				t.Errorf("ModelKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
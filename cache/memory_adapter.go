@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -68,6 +69,27 @@ func (m *MemoryAdapter) Exists(ctx context.Context, key string) (bool, error) {
 	return err == nil, nil
 }
 
+func (m *MemoryAdapter) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok || (item.expiration > 0 && time.Now().UnixNano() > item.expiration) {
+		item = memoryItem{value: "0"}
+		if ttl > 0 {
+			item.expiration = time.Now().Add(ttl).UnixNano()
+		}
+	}
+	n, err := strconv.ParseInt(item.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value at %s is not an integer", key)
+	}
+	n++
+	item.value = strconv.FormatInt(n, 10)
+	m.items[key] = item
+	return n, nil
+}
+
 func (m *MemoryAdapter) GetOrSet(ctx context.Context, key string, ttl time.Duration, fn func() (any, error)) (string, error) {
 	val, err := m.Get(ctx, key)
 	if err == nil {
@@ -154,12 +176,12 @@ func (m *MemoryAdapter) Subscribe(_ context.Context, _ string) (<-chan string, e
 	return make(chan string), nil // No-op in memory adapter
 }
 
-// SetModel caches data for a model using its CacheKey.
-func (m *MemoryAdapter) SetModel(ctx context.Context, model CacheKeyer, data any, ttl time.Duration) error {
-	return m.Set(ctx, model.CacheKey(), data, ttl)
+// SetModel caches data for a model under its ModelKey.
+func (m *MemoryAdapter) SetModel(ctx context.Context, model any, data any, ttl time.Duration) error {
+	return m.Set(ctx, ModelKey(model), data, ttl)
 }
 
-// GetModel retrieves cached data for a model using its CacheKey.
-func (m *MemoryAdapter) GetModel(ctx context.Context, model CacheKeyer) (string, error) {
-	return m.Get(ctx, model.CacheKey())
+// GetModel retrieves cached data for a model by its ModelKey.
+func (m *MemoryAdapter) GetModel(ctx context.Context, model any) (string, error) {
+	return m.Get(ctx, ModelKey(model))
 }
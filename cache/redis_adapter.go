@@ -30,8 +30,20 @@ func NewRedisAdapter(cfg config.RedisConfig) (*RedisAdapter, error) {
 
 	client := redis.NewClient(opts)
 
-	if err := client.Ping(context.Background()).Err(); err != nil {
-		return nil, fmt.Errorf("[Gails] ERROR: Cannot connect to Redis at %s — %v", cfg.URL, err)
+	// Retry with exponential backoff in case Redis starts after the app
+	attempts := cfg.ConnectRetry.Attempts()
+	for attempt := 1; ; attempt++ {
+		err := client.Ping(context.Background()).Err()
+		if err == nil {
+			break
+		}
+		if attempt >= attempts {
+			client.Close()
+			return nil, fmt.Errorf("[Gails] ERROR: Cannot connect to Redis at %s — %v", cfg.URL, err)
+		}
+		wait := cfg.ConnectRetry.Backoff(attempt)
+		log.Printf("[Gails] WARN: Redis not ready (attempt %d/%d), retrying in %s — %v", attempt, attempts, wait, err)
+		time.Sleep(wait)
 	}
 
 	Redis = client
@@ -68,6 +80,20 @@ func (r *RedisAdapter) Exists(ctx context.Context, key string) (bool, error) {
 	return n > 0, err
 }
 
+// incrScript increments KEYS[1] and, if it has no TTL yet, expires it after
+// ARGV[1] milliseconds. EXPIRE's NX option would do the same but needs Redis 7.
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if tonumber(ARGV[1]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+func (r *RedisAdapter) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, r.Client, []string{key}, ttl.Milliseconds()).Int64()
+}
+
 func (r *RedisAdapter) GetOrSet(ctx context.Context, key string, ttl time.Duration, fn func() (any, error)) (string, error) {
 	val, err := r.Get(ctx, key)
 	if err == nil {
@@ -127,12 +153,12 @@ func (r *RedisAdapter) Subscribe(ctx context.Context, channel string) (<-chan st
 	return ch, nil
 }
 
-// SetModel caches data for a model using its CacheKey.
-func (r *RedisAdapter) SetModel(ctx context.Context, model CacheKeyer, data any, ttl time.Duration) error {
-	return r.Set(ctx, model.CacheKey(), data, ttl)
+// SetModel caches data for a model under its ModelKey.
+func (r *RedisAdapter) SetModel(ctx context.Context, model any, data any, ttl time.Duration) error {
+	return r.Set(ctx, ModelKey(model), data, ttl)
 }
 
-// GetModel retrieves cached data for a model using its CacheKey.
-func (r *RedisAdapter) GetModel(ctx context.Context, model CacheKeyer) (string, error) {
-	return r.Get(ctx, model.CacheKey())
+// GetModel retrieves cached data for a model by its ModelKey.
+func (r *RedisAdapter) GetModel(ctx context.Context, model any) (string, error) {
+	return r.Get(ctx, ModelKey(model))
 }
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/boj/redistore"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/shaurya/gails/config"
 )
 
 // RedisSessionStore wraps redistore for Redis-backed session storage.
@@ -23,6 +25,40 @@ func NewRedisSessionStore(host string, port int, password string, secret string)
 	return &RedisSessionStore{store}, nil
 }
 
+// NewRedisSessionStoreFromConfig creates a Redis-backed session store on the
+// app's Redis, with keys under sessCfg.KeyPrefix and sessions expiring after
+// sessCfg.TTL seconds.
+func NewRedisSessionStoreFromConfig(redisCfg config.RedisConfig, sessCfg config.SessionConfig, secret string) (*RedisSessionStore, error) {
+	size := redisCfg.Pool
+	if size <= 0 {
+		size = 10
+	}
+	pool := &redigo.Pool{
+		MaxIdle:     size,
+		IdleTimeout: 240 * time.Second,
+		TestOnBorrow: func(c redigo.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+		Dial: func() (redigo.Conn, error) {
+			// A database in the URL path takes precedence over redis.db.
+			return redigo.DialURL(redisCfg.URL, redigo.DialDatabase(redisCfg.DB))
+		},
+	}
+	store, err := redistore.NewRediStoreWithPool(pool, []byte(secret))
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("[Gails] ERROR: Cannot connect to Redis session store at %s — %v", redisCfg.URL, err)
+	}
+	if sessCfg.KeyPrefix != "" {
+		store.SetKeyPrefix(sessCfg.KeyPrefix)
+	}
+	if sessCfg.TTL > 0 {
+		store.SetMaxAge(sessCfg.TTL)
+	}
+	return &RedisSessionStore{store}, nil
+}
+
 // Fragment caching helpers
 
 // GetFragment retrieves a cached fragment by key.
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shaurya/gails/generator"
+)
+
+// generateAuth scaffolds a session-based authentication flow: a User model,
+// registration/login/logout/password-reset controllers, views, a mailer and
+// the users migration.
+func generateAuth(g *generator.Generator) {
+	module, err := appModulePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[Gails] %v (run from your app root)\n", err)
+		os.Exit(1)
+	}
+	data := map[string]any{"Module": module}
+
+	mustWrite(g.GenerateInline(authUserModelTmpl, data, "app/models/user.go"))
+	mustWrite(g.GenerateInline(authRegistrationsTmpl, data, "app/controllers/registrations_controller.go"))
+	mustWrite(g.GenerateInline(authSessionsTmpl, data, "app/controllers/sessions_controller.go"))
+	mustWrite(g.GenerateInline(authPasswordsTmpl, data, "app/controllers/passwords_controller.go"))
+	mustWrite(g.GenerateInline(authMailerTmpl, data, "app/mailers/user_mailer.go"))
+
+	mustWrite(g.WriteFile("views/auth/register.html", authPage("Sign up", `<form method="POST" action="/register">
+  {{csrfToken .CSRFToken}}
+  <label>Email <input type="email" name="email" value="{{.Email}}" required></label>
+  <label>Password <input type="password" name="password" minlength="8" required></label>
+  <label>Confirm password <input type="password" name="password_confirmation" required></label>
+  <button type="submit">Sign up</button>
+</form>
+<p><a href="/login">Already have an account? Log in</a></p>`)))
+	mustWrite(g.WriteFile("views/auth/login.html", authPage("Log in", `<form method="POST" action="/login">
+  {{csrfToken .CSRFToken}}
+  <label>Email <input type="email" name="email" value="{{.Email}}" required></label>
+  <label>Password <input type="password" name="password" required></label>
+  <button type="submit">Log in</button>
+</form>
+<p><a href="/register">Sign up</a> · <a href="/password/forgot">Forgot your password?</a></p>`)))
+	mustWrite(g.WriteFile("views/auth/forgot_password.html", authPage("Forgot your password?", `<form method="POST" action="/password/forgot">
+  {{csrfToken .CSRFToken}}
+  <label>Email <input type="email" name="email" required></label>
+  <button type="submit">Send reset instructions</button>
+</form>`)))
+	mustWrite(g.WriteFile("views/auth/reset_password.html", authPage("Choose a new password", `<form method="POST" action="/password/reset">
+  {{csrfToken .CSRFToken}}
+  <input type="hidden" name="token" value="{{.Token}}">
+  <label>New password <input type="password" name="password" minlength="8" required></label>
+  <label>Confirm password <input type="password" name="password_confirmation" required></label>
+  <button type="submit">Update password</button>
+</form>`)))
+	mustWrite(g.WriteFile("views/mailers/user/password_reset.html", `<p>Hello {{.Email}},</p>
+<p>Someone requested a password reset for your account. The link below is valid for one hour:</p>
+<p><a href="{{.URL}}">Reset my password</a></p>
+<p>If you didn't request this, you can ignore this email.</p>
+`))
+
+	mustWrite(g.WriteFile(fmt.Sprintf("db/migrations/%s_create_users.sql", time.Now().Format("20060102150405")), `-- +goose Up
+CREATE TABLE users (
+	id SERIAL PRIMARY KEY,
+	email VARCHAR(255) NOT NULL,
+	password_digest VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW(),
+	deleted_at TIMESTAMP
+);
+CREATE UNIQUE INDEX index_users_on_email ON users (email);
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
+`))
+
+	fmt.Println("\n[Gails] Authentication scaffold complete")
+	fmt.Println("[Gails] Add to your routes:")
+	fmt.Printf(`
+	app.Routes(func(r *framework.Router) {
+		registrations := &controllers.RegistrationsController{}
+		sessions := &controllers.SessionsController{}
+		passwords := &controllers.PasswordsController{
+			Secret: app.Config.App.SecretKeyBase,
+			Mailer: &mailers.UserMailer{Mailer: mailer.Mailer{Config: app.Config.Mailer}},
+		}
+		r.GET("/register", registrations.New)
+		r.POST("/register", registrations.Create)
+		r.GET("/login", sessions.New)
+		r.POST("/login", sessions.Create)
+		r.POST("/logout", sessions.Destroy)
+		r.GET("/password/forgot", passwords.New)
+		r.POST("/password/forgot", passwords.Create)
+		r.GET("/password/reset", passwords.Edit)
+		r.POST("/password/reset", passwords.Update)
+	})
+`)
+	fmt.Println("\n[Gails] Then run: gails db migrate")
+}
+
+// appModulePath reads the module path from the app's go.mod.
+func appModulePath() (string, error) {
+	f, err := os.Open("go.mod")
+	if err != nil {
+		return "", fmt.Errorf("go.mod not found")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
+	}
+	return "", fmt.Errorf("no module declaration in go.mod")
+}
+
+func authPage(title, body string) string {
+	return `<!DOCTYPE html>
+<html>
+<head><title>` + title + `</title></head>
+<body>
+<h1>` + title + `</h1>
+{{with .Notice}}<p class="notice">{{.}}</p>{{end}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+` + body + `
+</body>
+</html>
+`
+}
+
+const authUserModelTmpl = `package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaurya/gails/auth"
+	"github.com/shaurya/gails/orm"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidResetToken is returned for malformed, expired or already-used reset tokens.
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+type User struct {
+	orm.Model
+	Email          string ` + "`" + `gorm:"uniqueIndex;not null" json:"email"` + "`" + `
+	PasswordDigest string ` + "`" + `gorm:"not null" json:"-"` + "`" + `
+}
+
+// SetPassword hashes and stores plain as the user's password.
+func (u *User) SetPassword(plain string) error {
+	digest, err := auth.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+	u.PasswordDigest = digest
+	return nil
+}
+
+// Authenticate reports whether plain matches the stored password.
+func (u *User) Authenticate(plain string) bool {
+	return auth.VerifyPassword(u.PasswordDigest, plain)
+}
+
+// FindUserByEmail looks up a user by (case-insensitive) email.
+func FindUserByEmail(db *gorm.DB, email string) (*User, error) {
+	var user User
+	if err := db.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PasswordResetToken returns a signed token valid for ttl. Signing over the
+// current password digest makes the token single-use: it stops verifying as
+// soon as the password changes.
+func (u *User) PasswordResetToken(secret string, ttl time.Duration) string {
+	payload := fmt.Sprintf("%d:%d", u.ID, time.Now().Add(ttl).Unix())
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + u.resetSignature(secret, payload)
+}
+
+// FindUserByResetToken verifies token and returns the user it was issued for.
+func FindUserByResetToken(db *gorm.DB, secret, token string) (*User, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidResetToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+	payload := string(raw)
+	idStr, expStr, ok := strings.Cut(payload, ":")
+	if !ok {
+		return nil, ErrInvalidResetToken
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, ErrInvalidResetToken
+	}
+
+	var user User
+	if err := db.First(&user, id).Error; err != nil {
+		return nil, ErrInvalidResetToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(user.resetSignature(secret, payload))) {
+		return nil, ErrInvalidResetToken
+	}
+	return &user, nil
+}
+
+func (u *User) resetSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload + ":" + u.PasswordDigest))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+`
+
+const authRegistrationsTmpl = `package controllers
+
+import (
+	"strings"
+
+	"github.com/shaurya/gails/auth"
+	"github.com/shaurya/gails/framework"
+
+	"{{.Module}}/app/models"
+)
+
+type RegistrationsController struct {
+	framework.Controller
+}
+
+type registrationParams struct {
+	Email                string ` + "`" + `json:"email" validate:"required,email"` + "`" + `
+	Password             string ` + "`" + `json:"password" validate:"required,min=8"` + "`" + `
+	PasswordConfirmation string ` + "`" + `json:"password_confirmation" validate:"required,eqfield=Password"` + "`" + `
+}
+
+// New renders the sign-up form.
+func (c *RegistrationsController) New(ctx *framework.Context) error {
+	return ctx.Render("register.html", framework.H{})
+}
+
+// Create registers a new user and logs them in.
+func (c *RegistrationsController) Create(ctx *framework.Context) error {
+	var params registrationParams
+	if err := ctx.Bind(&params); err != nil {
+		return ctx.Render("register.html", framework.H{"Email": params.Email, "Error": "Please check your email and password (at least 8 characters, confirmed)."})
+	}
+
+	if _, err := models.FindUserByEmail(ctx.DB(), params.Email); err == nil {
+		return ctx.Render("register.html", framework.H{"Email": params.Email, "Error": "That email is already registered."})
+	}
+
+	user := models.User{Email: strings.ToLower(strings.TrimSpace(params.Email))}
+	if err := user.SetPassword(params.Password); err != nil {
+		return ctx.InternalError(err)
+	}
+	if err := ctx.DB().Create(&user).Error; err != nil {
+		return ctx.InternalError(err)
+	}
+
+	if err := auth.Login(ctx.Response, ctx.Request, user.ID); err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Redirect("/")
+}
+`
+
+const authSessionsTmpl = `package controllers
+
+import (
+	"github.com/shaurya/gails/auth"
+	"github.com/shaurya/gails/framework"
+
+	"{{.Module}}/app/models"
+)
+
+type SessionsController struct {
+	framework.Controller
+}
+
+type loginParams struct {
+	Email    string ` + "`" + `json:"email" validate:"required"` + "`" + `
+	Password string ` + "`" + `json:"password" validate:"required"` + "`" + `
+}
+
+// New renders the login form.
+func (c *SessionsController) New(ctx *framework.Context) error {
+	return ctx.Render("login.html", framework.H{})
+}
+
+// Create logs the user in.
+func (c *SessionsController) Create(ctx *framework.Context) error {
+	var params loginParams
+	if err := ctx.Bind(&params); err != nil {
+		return ctx.Render("login.html", framework.H{"Email": params.Email, "Error": "Invalid email or password."})
+	}
+
+	keys := auth.LoginKeys(ctx.Request, params.Email)
+	if auth.IsLockedOut(keys...) {
+		return ctx.Render("login.html", framework.H{"Email": params.Email, "Error": "Too many failed attempts. Please try again later."})
+	}
+
+	user, err := models.FindUserByEmail(ctx.DB(), params.Email)
+	if err != nil || !user.Authenticate(params.Password) {
+		auth.RecordFailedLogin(keys...)
+		return ctx.Render("login.html", framework.H{"Email": params.Email, "Error": "Invalid email or password."})
+	}
+	auth.ResetFailedLogins(keys[0])
+
+	if err := auth.Login(ctx.Response, ctx.Request, user.ID); err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Redirect("/")
+}
+
+// Destroy logs the user out.
+func (c *SessionsController) Destroy(ctx *framework.Context) error {
+	if err := auth.Logout(ctx.Response, ctx.Request); err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Redirect("/login")
+}
+`
+
+const authPasswordsTmpl = `package controllers
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/shaurya/gails/framework"
+
+	"{{.Module}}/app/mailers"
+	"{{.Module}}/app/models"
+)
+
+// passwordResetTTL is how long a password reset link stays valid.
+const passwordResetTTL = time.Hour
+
+type PasswordsController struct {
+	framework.Controller
+	Secret string
+	Mailer *mailers.UserMailer
+}
+
+type resetParams struct {
+	Token                string ` + "`" + `json:"token" validate:"required"` + "`" + `
+	Password             string ` + "`" + `json:"password" validate:"required,min=8"` + "`" + `
+	PasswordConfirmation string ` + "`" + `json:"password_confirmation" validate:"required,eqfield=Password"` + "`" + `
+}
+
+// New renders the forgot-password form.
+func (c *PasswordsController) New(ctx *framework.Context) error {
+	return ctx.Render("forgot_password.html", framework.H{})
+}
+
+// Create emails a reset link. The response is the same whether or not the
+// email is registered, so the form can't be used to probe for accounts.
+func (c *PasswordsController) Create(ctx *framework.Context) error {
+	var params struct {
+		Email string ` + "`" + `json:"email"` + "`" + `
+	}
+	ctx.BindForm(&params)
+
+	if user, err := models.FindUserByEmail(ctx.DB(), params.Email); err == nil {
+		token := user.PasswordResetToken(c.Secret, passwordResetTTL)
+		link := resetURL(ctx, token)
+		if err := c.Mailer.PasswordReset(user, link).Deliver(); err != nil {
+			return ctx.InternalError(err)
+		}
+	}
+	return ctx.Render("forgot_password.html", framework.H{"Notice": "If that email is registered, reset instructions are on their way."})
+}
+
+// Edit renders the reset form for a valid token.
+func (c *PasswordsController) Edit(ctx *framework.Context) error {
+	token := ctx.Query("token")
+	if _, err := models.FindUserByResetToken(ctx.DB(), c.Secret, token); err != nil {
+		return ctx.Render("forgot_password.html", framework.H{"Error": "That reset link is invalid or has expired."})
+	}
+	return ctx.Render("reset_password.html", framework.H{"Token": token})
+}
+
+// Update sets the new password.
+func (c *PasswordsController) Update(ctx *framework.Context) error {
+	var params resetParams
+	if err := ctx.Bind(&params); err != nil {
+		return ctx.Render("reset_password.html", framework.H{"Token": params.Token, "Error": "Passwords must match and be at least 8 characters."})
+	}
+
+	user, err := models.FindUserByResetToken(ctx.DB(), c.Secret, params.Token)
+	if err != nil {
+		return ctx.Render("forgot_password.html", framework.H{"Error": "That reset link is invalid or has expired."})
+	}
+	if err := user.SetPassword(params.Password); err != nil {
+		return ctx.InternalError(err)
+	}
+	if err := ctx.DB().Model(user).Update("password_digest", user.PasswordDigest).Error; err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Render("login.html", framework.H{"Notice": "Your password has been updated. Please log in."})
+}
+
+func resetURL(ctx *framework.Context, token string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.Request.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host + "/password/reset?token=" + url.QueryEscape(token)
+}
+`
+
+const authMailerTmpl = `package mailers
+
+import (
+	"github.com/shaurya/gails/framework"
+	"github.com/shaurya/gails/mailer"
+
+	"{{.Module}}/app/models"
+)
+
+type UserMailer struct {
+	mailer.Mailer
+}
+
+// PasswordReset emails the user a link to choose a new password.
+func (m *UserMailer) PasswordReset(user *models.User, url string) *mailer.Email {
+	return m.NewEmail().
+		To(user.Email).
+		Subject("Reset your password").
+		Template("user/password_reset", framework.H{"Email": user.Email, "URL": url})
+}
+`
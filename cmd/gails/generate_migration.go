@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaurya/gails/generator"
+)
+
+// migrationKind is the kind of schema change a migration name describes.
+type migrationKind int
+
+const (
+	createTable migrationKind = iota
+	addColumns
+	removeColumns
+	addIndex
+)
+
+// parseMigrationName infers what a migration does, and to which table, from
+// its snake_cased name, Rails-style:
+//
+//	create_posts           -> createTable, "posts"
+//	add_age_to_users       -> addColumns, "users"
+//	remove_age_from_users  -> removeColumns, "users"
+//	add_index_to_users     -> addIndex, "users"
+//
+// Names matching none of these are treated as create_<name>.
+func parseMigrationName(snake string) (migrationKind, string) {
+	switch {
+	case strings.HasPrefix(snake, "add_index_to_"):
+		return addIndex, strings.TrimPrefix(snake, "add_index_to_")
+	case strings.HasPrefix(snake, "add_") && strings.Contains(snake, "_to_"):
+		return addColumns, snake[strings.LastIndex(snake, "_to_")+len("_to_"):]
+	case strings.HasPrefix(snake, "remove_") && strings.Contains(snake, "_from_"):
+		return removeColumns, snake[strings.LastIndex(snake, "_from_")+len("_from_"):]
+	}
+	return createTable, strings.TrimPrefix(snake, "create_")
+}
+
+// generateMigration writes a goose SQL migration for the change described by
+// name (see parseMigrationName), with fields as the columns involved:
+//
+//	gails g migration create_posts title:string user:references
+//	gails g migration AddAgeToUsers age:integer
+//	gails g migration RemoveAgeFromUsers age:integer
+//	gails g migration add_index_to_users email:string:unique
+//
+// Each Up gets the matching Down, so the migration can be rolled back. Only
+// create_* migrations may have no fields; the others would be empty.
+func generateMigration(g *generator.Generator, name string, fields []generator.Field) error {
+	snake := generator.SnakeCase(name)
+	kind, table := parseMigrationName(snake)
+	if kind != createTable && len(fields) == 0 {
+		return fmt.Errorf("migration %s names no columns; list them as name:type, e.g. email:string", snake)
+	}
+	var up, down []string
+
+	switch kind {
+	case addIndex:
+		for _, f := range fields {
+			up = append(up, createIndexSQL(table, f))
+		}
+		for i := len(fields) - 1; i >= 0; i-- {
+			down = append(down, fmt.Sprintf("DROP INDEX IF EXISTS %s;", indexName(table, fields[i])))
+		}
+
+	case addColumns:
+		for _, f := range fields {
+			up = append(up, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, columnDefinition(f)))
+			if f.References != "" {
+				up = append(up, createIndexSQL(table, f))
+			}
+		}
+		for i := len(fields) - 1; i >= 0; i-- {
+			down = append(down, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, generator.SnakeCase(fields[i].Name)))
+		}
+
+	case removeColumns:
+		for _, f := range fields {
+			up = append(up, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", table, generator.SnakeCase(f.Name)))
+		}
+		// Restoring the column needs its type, so remove_* migrations should
+		// list the fields the same way add_* ones do.
+		for i := len(fields) - 1; i >= 0; i-- {
+			down = append(down, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, columnDefinition(fields[i])))
+		}
+
+	default:
+		var columns string
+		for _, f := range fields {
+			columns += "\t" + columnDefinition(f) + ",\n"
+		}
+		up = append(up, fmt.Sprintf(`CREATE TABLE %s (
+	id SERIAL PRIMARY KEY,
+%s	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW(),
+	deleted_at TIMESTAMP
+);`, table, columns))
+		for _, f := range fields {
+			if f.References != "" {
+				up = append(up, createIndexSQL(table, f))
+			}
+		}
+		down = append(down, fmt.Sprintf("DROP TABLE IF EXISTS %s;", table))
+		snake = "create_" + table
+	}
+
+	migrationSQL := fmt.Sprintf("-- +goose Up\n%s\n\n-- +goose Down\n%s\n", strings.Join(up, "\n"), strings.Join(down, "\n"))
+	path := fmt.Sprintf("db/migrations/%s_%s.sql", time.Now().Format("20060102150405"), snake)
+	return g.WriteFile(path, migrationSQL)
+}
+
+// columnDefinition renders a field as "name TYPE [constraints]".
+func columnDefinition(f generator.Field) string {
+	def := generator.SnakeCase(f.Name) + " " + goTypeToSQL(f.Type)
+	if strings.Contains(f.GormTag, "uniqueIndex") {
+		def += " UNIQUE"
+	}
+	if strings.Contains(f.GormTag, "not null") {
+		def += " NOT NULL"
+	}
+	if f.References != "" {
+		def += fmt.Sprintf(" REFERENCES %s(id)", f.References)
+	}
+	return def
+}
+
+// createIndexSQL indexes f's column. Foreign keys get one automatically since
+// Postgres doesn't index them for you; a :unique field gets a unique index.
+func createIndexSQL(table string, f generator.Field) string {
+	unique := ""
+	if strings.Contains(f.GormTag, "uniqueIndex") {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);", unique, indexName(table, f), table, generator.SnakeCase(f.Name))
+}
+
+func indexName(table string, f generator.Field) string {
+	return fmt.Sprintf("index_%s_on_%s", table, generator.SnakeCase(f.Name))
+}
+
+func goTypeToSQL(goType string) string {
+	switch goType {
+	case "string":
+		return "VARCHAR(255)"
+	case "int":
+		return "INTEGER"
+	case "uint":
+		return "BIGINT"
+	case "float64":
+		return "DOUBLE PRECISION"
+	case "bool":
+		return "BOOLEAN DEFAULT false"
+	case "time.Time":
+		return "TIMESTAMP"
+	default:
+		return "VARCHAR(255)"
+	}
+}
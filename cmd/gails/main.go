@@ -1,10 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/shaurya/gails/db"
 	"github.com/shaurya/gails/framework"
@@ -33,6 +33,7 @@ func main() {
 
 	// Introspection
 	rootCmd.AddCommand(routesCmd())
+	rootCmd.AddCommand(consoleCmd())
 	rootCmd.AddCommand(versionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -90,15 +91,26 @@ func workerCmd() *cobra.Command {
 // --- Generators ---
 
 func generateCmd() *cobra.Command {
+	var force bool
 	cmd := &cobra.Command{
 		Use:     "generate [type] [name] [fields...]",
 		Aliases: []string{"g"},
-		Short:   "Generate code (model, controller, scaffold, migration, mailer, job)",
-		Args:    cobra.MinimumNArgs(2),
+		Short:   "Generate code (model, controller, scaffold, migration, mailer, job, auth)",
+		Args:    cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			genType := args[0]
-			name := args[1]
 			g := generator.NewGenerator("generator/templates")
+			g.Force = force
+
+			if genType == "auth" {
+				generateAuth(g)
+				return
+			}
+			if len(args) < 2 {
+				fmt.Fprintf(os.Stderr, "Usage: gails generate %s [name] [fields...]\n", genType)
+				os.Exit(1)
+			}
+			name := args[1]
 
 			switch genType {
 			case "model":
@@ -111,15 +123,15 @@ func generateCmd() *cobra.Command {
 			case "scaffold":
 				fields := g.ParseFields(args[2:])
 				generateModel(g, name, fields)
-				generateController(g, name, []string{"index", "show", "new", "create", "edit", "update", "destroy"})
+				generateScaffoldController(g, name, fields)
 				generateViews(g, name, fields)
-				generateMigration(g, name, fields)
+				mustWrite(generateMigration(g, "create_"+generator.Pluralize(generator.SnakeCase(name)), fields))
 				fmt.Printf("\n[Gails] Scaffold complete for %s\n", name)
-				fmt.Printf("[Gails] Add to your routes: r.Resources(\"%s\", &%sController{})\n", strings.ToLower(name)+"s", name)
+				fmt.Printf("[Gails] Add to your routes: r.Resources(\"%s\", &%sController{})\n", generator.Pluralize(generator.SnakeCase(name)), generator.PascalCase(name))
 
 			case "migration":
 				fields := g.ParseFields(args[2:])
-				generateMigration(g, name, fields)
+				mustWrite(generateMigration(g, name, fields))
 
 			case "mailer":
 				actions := args[2:]
@@ -130,27 +142,41 @@ func generateCmd() *cobra.Command {
 
 			default:
 				fmt.Printf("Unknown generator type: %s\n", genType)
-				fmt.Println("Available: model, controller, scaffold, migration, mailer, job")
+				fmt.Println("Available: model, controller, scaffold, migration, mailer, job, auth")
 			}
 		},
 	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite files that already exist")
 	return cmd
 }
 
+// mustWrite stops the generator on the first file it fails to write, so a
+// half-generated resource isn't reported as complete.
+func mustWrite(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[Gails] %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func generateModel(g *generator.Generator, name string, fields []generator.Field) {
 	tmpl := `package models
 
 import (
+{{- if .NeedsTime}}
+	"time"
+
+{{end}}
 	"github.com/shaurya/gails/orm"
 )
 
 type {{.Name}} struct {
 	orm.Model
-{{range .Fields}}	{{.Name}} {{.Type}}` + " `" + `{{if .GormTag}}gorm:"{{.GormTag}}"{{end}}{{if .ValidateTag}} validate:"{{.ValidateTag}}"{{end}}` + "`" + `
+{{range .Fields}}	{{.Name}} {{.Type}}{{if or .GormTag .ValidateTag}} ` + "`" + `{{if .GormTag}}gorm:"{{.GormTag}}"{{end}}{{if and .GormTag .ValidateTag}} {{end}}{{if .ValidateTag}}validate:"{{.ValidateTag}}"{{end}}` + "`" + `{{end}}
 {{end}}}
 `
-	data := map[string]any{"Name": name, "Fields": fields}
-	g.GenerateInline(tmpl, data, fmt.Sprintf("app/models/%s.go", strings.ToLower(name)))
+	data := map[string]any{"Name": generator.PascalCase(name), "Fields": fields, "NeedsTime": usesTime(fields)}
+	mustWrite(g.GenerateInline(tmpl, data, fmt.Sprintf("app/models/%s.go", generator.SnakeCase(name))))
 }
 
 func generateController(g *generator.Generator, name string, actions []string) {
@@ -165,28 +191,189 @@ type {{.Name}}Controller struct {
 	framework.Controller
 }
 {{range .Actions}}
-func (c *{{$.Name}}Controller) {{.}}(ctx *framework.Context) error {
+func (c *{{$.Name}}Controller) {{PascalCase .}}(ctx *framework.Context) error {
 	return ctx.JSON(http.StatusOK, framework.H{"action": "{{.}}"})
 }
 {{end}}`
 
-	// Capitalize action names
-	var caps []string
-	for _, a := range actions {
-		caps = append(caps, strings.Title(a))
+	data := map[string]any{"Name": generator.PascalCase(name), "Actions": actions}
+	mustWrite(g.GenerateInline(tmpl, data, fmt.Sprintf("app/controllers/%s_controller.go", generator.SnakeCase(name))))
+}
+
+// generateScaffoldController writes a JSON resource controller backed by
+// orm.Query for the model generated alongside it.
+func generateScaffoldController(g *generator.Generator, name string, fields []generator.Field) {
+	module, err := appModulePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[Gails] %v (run from your app root)\n", err)
+		os.Exit(1)
+	}
+
+	tmpl := `package controllers
+
+import (
+	"net/http"
+	"strconv"
+{{- if .NeedsTime}}
+	"time"
+{{- end}}
+
+	"github.com/shaurya/gails/framework"
+	"github.com/shaurya/gails/orm"
+
+	"{{.Module}}/app/models"
+)
+
+type {{.Name}}Controller struct {
+	framework.Controller
+}
+
+// {{.Var}}Params are the attributes accepted by Create and Update.
+type {{.Var}}Params struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{SnakeCase .Name}}"{{if .ValidateTag}} validate:"{{.ValidateTag}}"{{end}}` + "`" + `
+{{- end}}
+}
+
+// {{.Var}}ListParams are the query parameters accepted by Index.
+type {{.Var}}ListParams struct {
+	Page    int ` + "`" + `query:"page" validate:"omitempty,min=1"` + "`" + `
+	PerPage int ` + "`" + `query:"per_page" validate:"omitempty,min=1,max=100"` + "`" + `
+}
+
+func (p {{.Var}}Params) apply(record *models.{{.Name}}) {
+{{- range .Fields}}
+	record.{{.Name}} = p.{{.Name}}
+{{- end}}
+}
+
+// Index lists {{.Singular}} records a page at a time: GET /{{.Plural}}?page=2&per_page=50
+func (c *{{.Name}}Controller) Index(ctx *framework.Context) error {
+	var params {{.Var}}ListParams
+	if err := ctx.BindQuery(&params); err != nil {
+		return err
 	}
 
-	data := map[string]any{"Name": name, "Actions": caps}
-	g.GenerateInline(tmpl, data, fmt.Sprintf("app/controllers/%s_controller.go", strings.ToLower(name)))
+	records, err := orm.Query[models.{{.Name}}](ctx.DB()).Order("id").Page(params.Page).PerPage(params.PerPage).All()
+	if err != nil {
+		return ctx.InternalError(err)
+	}
+	total, err := orm.Query[models.{{.Name}}](ctx.DB()).Count()
+	if err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.JSON(http.StatusOK, framework.H{"data": records, "total": total})
+}
+
+// Show returns a single {{.Singular}}: GET /{{.Plural}}/{id}
+func (c *{{.Name}}Controller) Show(ctx *framework.Context) error {
+	record, err := find{{.Name}}(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, record)
+}
+
+// Create saves a new {{.Singular}}: POST /{{.Plural}}
+func (c *{{.Name}}Controller) Create(ctx *framework.Context) error {
+	var params {{.Var}}Params
+	if err := ctx.Bind(&params); err != nil {
+		return err
+	}
+
+	var record models.{{.Name}}
+	params.apply(&record)
+	if err := orm.Query[models.{{.Name}}](ctx.DB()).Create(&record); err != nil {
+		if errs := orm.HandleDBError(err); errs != nil {
+			return ctx.UnprocessableEntity(errs)
+		}
+		return ctx.InternalError(err)
+	}
+	return ctx.JSON(http.StatusCreated, record)
+}
+
+// Update changes an existing {{.Singular}}: PUT/PATCH /{{.Plural}}/{id}
+func (c *{{.Name}}Controller) Update(ctx *framework.Context) error {
+	record, err := find{{.Name}}(ctx)
+	if err != nil {
+		return err
+	}
+
+	var params {{.Var}}Params
+	if err := ctx.Bind(&params); err != nil {
+		return err
+	}
+
+	params.apply(record)
+	if err := orm.Query[models.{{.Name}}](ctx.DB()).Update(record); err != nil {
+		if errs := orm.HandleDBError(err); errs != nil {
+			return ctx.UnprocessableEntity(errs)
+		}
+		return ctx.InternalError(err)
+	}
+	return ctx.JSON(http.StatusOK, record)
+}
+
+// Destroy deletes a {{.Singular}}: DELETE /{{.Plural}}/{id}
+func (c *{{.Name}}Controller) Destroy(ctx *framework.Context) error {
+	record, err := find{{.Name}}(ctx)
+	if err != nil {
+		return err
+	}
+	if err := orm.Query[models.{{.Name}}](ctx.DB()).Delete(record); err != nil {
+		return ctx.InternalError(err)
+	}
+	return ctx.Status(http.StatusNoContent)
+}
+
+// find{{.Name}} loads the {{.Singular}} named by the {id} route parameter, or
+// returns a 404.
+func find{{.Name}}(ctx *framework.Context) (*models.{{.Name}}, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, ctx.NotFound("{{.Name}} not found")
+	}
+	record, err := orm.Query[models.{{.Name}}](ctx.DB()).Find(id)
+	if orm.IsNotFound(err) {
+		return nil, ctx.NotFound("{{.Name}} not found")
+	}
+	if err != nil {
+		return nil, ctx.InternalError(err)
+	}
+	return record, nil
+}
+`
+
+	data := map[string]any{
+		"Module":    module,
+		"Name":      generator.PascalCase(name),
+		"Var":       generator.CamelCase(name),
+		"Singular":  strings.ReplaceAll(generator.SnakeCase(name), "_", " "),
+		"Plural":    generator.Pluralize(generator.SnakeCase(name)),
+		"Fields":    fields,
+		"NeedsTime": usesTime(fields),
+	}
+	mustWrite(g.GenerateInline(tmpl, data, fmt.Sprintf("app/controllers/%s_controller.go", generator.SnakeCase(name))))
+}
+
+// usesTime reports whether any field needs the "time" import.
+func usesTime(fields []generator.Field) bool {
+	for _, f := range fields {
+		if f.Type == "time.Time" {
+			return true
+		}
+	}
+	return false
 }
 
 func generateViews(g *generator.Generator, name string, fields []generator.Field) {
-	lower := strings.ToLower(name)
+	plural := generator.Pluralize(generator.SnakeCase(name))
+	name = generator.PascalCase(name)
 
 	// Index view
 	indexTmpl := `{{define "content"}}
-<h1>` + name + `s</h1>
-<a href="/` + lower + `s/new">New ` + name + `</a>
+<h1>` + generator.Pluralize(name) + `</h1>
+<a href="/` + plural + `/new">New ` + name + `</a>
 <table>
 <thead><tr>` + func() string {
 		var h string
@@ -198,45 +385,12 @@ func generateViews(g *generator.Generator, name string, fields []generator.Field
 <tbody></tbody>
 </table>
 {{end}}`
-	writeFile(fmt.Sprintf("views/%s/index.html", lower+"s"), indexTmpl)
+	mustWrite(g.WriteFile(fmt.Sprintf("views/%s/index.html", plural), indexTmpl))
 
 	// Show, New, Edit views
-	writeFile(fmt.Sprintf("views/%s/show.html", lower+"s"), `{{define "content"}}<h1>`+name+` Details</h1>{{end}}`)
-	writeFile(fmt.Sprintf("views/%s/new.html", lower+"s"), `{{define "content"}}<h1>New `+name+`</h1>{{end}}`)
-	writeFile(fmt.Sprintf("views/%s/edit.html", lower+"s"), `{{define "content"}}<h1>Edit `+name+`</h1>{{end}}`)
-}
-
-func generateMigration(g *generator.Generator, name string, fields []generator.Field) {
-	timestamp := time.Now().Format("20060102150405")
-	lower := strings.ToLower(name) + "s"
-
-	var columns string
-	for _, f := range fields {
-		sqlType := goTypeToSQL(f.Type)
-		columns += fmt.Sprintf("\t\t%s %s", strings.ToLower(f.Name), sqlType)
-		if f.GormTag != "" && strings.Contains(f.GormTag, "uniqueIndex") {
-			columns += " UNIQUE"
-		}
-		if f.GormTag != "" && strings.Contains(f.GormTag, "not null") {
-			columns += " NOT NULL"
-		}
-		columns += ",\n"
-	}
-
-	migrationSQL := fmt.Sprintf(`-- +goose Up
-CREATE TABLE %s (
-	id SERIAL PRIMARY KEY,
-%s	created_at TIMESTAMP DEFAULT NOW(),
-	updated_at TIMESTAMP DEFAULT NOW(),
-	deleted_at TIMESTAMP
-);
-
--- +goose Down
-DROP TABLE IF EXISTS %s;
-`, lower, columns, lower)
-
-	path := fmt.Sprintf("db/migrations/%s_create_%s.sql", timestamp, lower)
-	writeFile(path, migrationSQL)
+	mustWrite(g.WriteFile(fmt.Sprintf("views/%s/show.html", plural), `{{define "content"}}<h1>`+name+` Details</h1>{{end}}`))
+	mustWrite(g.WriteFile(fmt.Sprintf("views/%s/new.html", plural), `{{define "content"}}<h1>New `+name+`</h1>{{end}}`))
+	mustWrite(g.WriteFile(fmt.Sprintf("views/%s/edit.html", plural), `{{define "content"}}<h1>Edit `+name+`</h1>{{end}}`))
 }
 
 func generateMailer(g *generator.Generator, name string, actions []string) {
@@ -251,22 +405,22 @@ type {{.Name}}Mailer struct {
 	mailer.Mailer
 }
 {{range .Actions}}
-func (m {{$.Name}}Mailer) {{.}}(data framework.H) *mailer.Email {
+func (m {{$.Name}}Mailer) {{PascalCase .}}(data framework.H) *mailer.Email {
 	return m.NewEmail().
 		Subject("{{.}}").
 		Template("{{$.LowerName}}/{{.}}", data)
 }
 {{end}}`
 	data := map[string]any{
-		"Name":      name,
-		"LowerName": strings.ToLower(name),
+		"Name":      generator.PascalCase(name),
+		"LowerName": generator.SnakeCase(name),
 		"Actions":   actions,
 	}
-	g.GenerateInline(tmpl, data, fmt.Sprintf("app/mailers/%s_mailer.go", strings.ToLower(name)))
+	mustWrite(g.GenerateInline(tmpl, data, fmt.Sprintf("app/mailers/%s_mailer.go", generator.SnakeCase(name))))
 
 	// Create template files for each action
 	for _, action := range actions {
-		writeFile(fmt.Sprintf("views/mailers/%s/%s.html", strings.ToLower(name), action), fmt.Sprintf("<h1>%s</h1>\n<p>Email content here.</p>", action))
+		mustWrite(g.WriteFile(fmt.Sprintf("views/mailers/%s/%s.html", generator.SnakeCase(name), action), fmt.Sprintf("<h1>%s</h1>\n<p>Email content here.</p>", action)))
 	}
 }
 
@@ -287,30 +441,8 @@ func (j *{{.Name}}Job) Perform(ctx context.Context) error {
 	return nil
 }
 `
-	data := map[string]any{"Name": name}
-	g.GenerateInline(tmpl, data, fmt.Sprintf("app/jobs/%s_job.go", strings.ToLower(name)))
-}
-
-func goTypeToSQL(goType string) string {
-	switch goType {
-	case "string":
-		return "VARCHAR(255)"
-	case "int":
-		return "INTEGER"
-	case "float64":
-		return "DOUBLE PRECISION"
-	case "bool":
-		return "BOOLEAN DEFAULT false"
-	case "time.Time":
-		return "TIMESTAMP"
-	default:
-		return "VARCHAR(255)"
-	}
-}
-
-func writeFile(path, content string) {
-	os.MkdirAll(fmt.Sprintf("%s", path[:strings.LastIndex(path, "/")]), 0755)
-	os.WriteFile(path, []byte(content), 0644)
+	data := map[string]any{"Name": generator.PascalCase(name)}
+	mustWrite(g.GenerateInline(tmpl, data, fmt.Sprintf("app/jobs/%s_job.go", generator.SnakeCase(name))))
 }
 
 // --- Database ---
@@ -321,27 +453,45 @@ func dbCmd() *cobra.Command {
 		Short: "Database management commands",
 	}
 
-	cmd.AddCommand(&cobra.Command{
+	var migrateVersion int64
+	migrateCmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Run pending migrations",
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg, _ := framework.LoadConfig()
 			database := db.MustConnect(cfg.Database)
-			if err := db.Migrate(database, "db/migrations"); err != nil {
+			var err error
+			if cmd.Flags().Changed("version") {
+				err = db.MigrateTo(database, "db/migrations", migrateVersion)
+			} else {
+				err = db.Migrate(database, "db/migrations")
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
 				os.Exit(1)
 			}
 			fmt.Println("[Gails] Migrations complete")
 		},
-	})
+	}
+	migrateCmd.Flags().Int64Var(&migrateVersion, "version", 0, "Migrate up to this version only")
+	cmd.AddCommand(migrateCmd)
 
 	var steps int
+	var rollbackVersion int64
 	rollbackCmd := &cobra.Command{
 		Use:   "rollback",
 		Short: "Rollback migrations",
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg, _ := framework.LoadConfig()
 			database := db.MustConnect(cfg.Database)
+			if cmd.Flags().Changed("version") {
+				if err := db.RollbackTo(database, "db/migrations", rollbackVersion); err != nil {
+					fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("[Gails] Rolled back to version %d\n", rollbackVersion)
+				return
+			}
 			if err := db.Rollback(database, "db/migrations", steps); err != nil {
 				fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
 				os.Exit(1)
@@ -350,8 +500,23 @@ func dbCmd() *cobra.Command {
 		},
 	}
 	rollbackCmd.Flags().IntVar(&steps, "steps", 1, "Number of migrations to roll back")
+	rollbackCmd.Flags().Int64Var(&rollbackVersion, "version", 0, "Roll back every migration newer than this version")
 	cmd.AddCommand(rollbackCmd)
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "redo",
+		Short: "Roll back and re-run the latest migration",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, _ := framework.LoadConfig()
+			database := db.MustConnect(cfg.Database)
+			if err := db.Redo(database, "db/migrations"); err != nil {
+				fmt.Fprintf(os.Stderr, "Redo failed: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("[Gails] Redo complete")
+		},
+	})
+
 	cmd.AddCommand(&cobra.Command{
 		Use:   "status",
 		Short: "Print migration status",
@@ -362,6 +527,30 @@ func dbCmd() *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "schema:dump",
+		Short: "Write the current database schema to " + db.SchemaFile,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, _ := framework.LoadConfig()
+			if err := db.DumpSchema(cfg.Database, db.SchemaFile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "schema:load",
+		Short: "Load " + db.SchemaFile + " into the database instead of running migrations",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, _ := framework.LoadConfig()
+			if err := db.LoadSchema(cfg.Database, db.SchemaFile); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		},
+	})
+
 	cmd.AddCommand(&cobra.Command{
 		Use:   "create",
 		Short: "Create the database",
@@ -388,9 +577,13 @@ func dbCmd() *cobra.Command {
 
 	cmd.AddCommand(&cobra.Command{
 		Use:   "seed",
-		Short: "Run seed data",
+		Short: "Run SeedDB from " + seedFile,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("[Gails] Run your seed file manually or call db.Seed(db, seedFn)")
+			if err := runSeeds(); err != nil {
+				fmt.Fprintf(os.Stderr, "[Gails] %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("[Gails] Seeding complete")
 		},
 	})
 
@@ -403,6 +596,10 @@ func dbCmd() *cobra.Command {
 			db.CreateDB(cfg.Database.Name, cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.SSLMode)
 			database := db.MustConnect(cfg.Database)
 			db.Migrate(database, "db/migrations")
+			if err := runSeeds(); err != nil {
+				fmt.Fprintf(os.Stderr, "[Gails] %v\n", err)
+				os.Exit(1)
+			}
 			fmt.Println("[Gails] Database reset complete")
 		},
 	})
@@ -413,14 +610,30 @@ func dbCmd() *cobra.Command {
 // --- Routes ---
 
 func routesCmd() *cobra.Command {
-	return &cobra.Command{
+	var asJSON, withMiddleware bool
+	cmd := &cobra.Command{
 		Use:   "routes",
 		Short: "Print all registered routes",
 		Run: func(cmd *cobra.Command, args []string) {
 			app := framework.New()
-			fmt.Println(app.Router.Inspect())
+			switch {
+			case asJSON:
+				out, err := json.MarshalIndent(app.Router.GetRoutes(), "", "  ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to encode routes: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(out))
+			case withMiddleware:
+				fmt.Println(app.Router.InspectMiddleware())
+			default:
+				fmt.Println(app.Router.Inspect())
+			}
 		},
 	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print routes as JSON, including each route's middleware")
+	cmd.Flags().BoolVarP(&withMiddleware, "middleware", "m", false, "Show each route's middleware stack")
+	return cmd
 }
 
 // --- New App ---
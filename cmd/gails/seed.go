@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// seedFile is the app's seed file; the skeleton generates it with a SeedDB
+// function in package main.
+const seedFile = "db/seeds.go"
+
+// seedRunnerDir is where runSeeds builds the seed program. It must be inside
+// the app's module so seeds can import the app's own packages.
+const seedRunnerDir = "tmp/gails-seed"
+
+// seedMain calls the app's SeedDB with a database connected from config.
+const seedMain = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shaurya/gails/db"
+	"github.com/shaurya/gails/framework"
+)
+
+func main() {
+	cfg, err := framework.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	database, err := db.Connect(cfg.Database)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := db.Seed(database, SeedDB); err != nil {
+		fmt.Fprintf(os.Stderr, "[Gails] Seeding failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+`
+
+// runSeeds compiles db/seeds.go together with a small main and runs it, so
+// SeedDB runs against the configured database in the current APP_ENV.
+func runSeeds() error {
+	src, err := os.ReadFile(seedFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no %s found; define func SeedDB(db *gorm.DB) error in it", seedFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(seedRunnerDir, 0755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(seedRunnerDir)
+	if err := os.WriteFile(filepath.Join(seedRunnerDir, "seeds.go"), src, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(seedRunnerDir, "main.go"), []byte(seedMain), 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+seedRunnerDir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", seedFile, err)
+	}
+	return nil
+}
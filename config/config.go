@@ -1,13 +1,23 @@
 package config
 
+import (
+	"math/rand/v2"
+	"time"
+)
+
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Queue    QueueConfig    `mapstructure:"queue"`
-	Mailer   MailerConfig   `mapstructure:"mailer"`
-	Cache    CacheConfig    `mapstructure:"cache"`
-	Sessions SessionConfig  `mapstructure:"sessions"`
+	App       AppConfig                      `mapstructure:"app"`
+	Database  DatabaseConfig                 `mapstructure:"database"`
+	Redis     RedisConfig                    `mapstructure:"redis"`
+	Queue     QueueConfig                    `mapstructure:"queue"`
+	Mailer    MailerConfig                   `mapstructure:"mailer"`
+	Cache     CacheConfig                    `mapstructure:"cache"`
+	Sessions  SessionConfig                  `mapstructure:"sessions"`
+	Uploads   UploadConfig                   `mapstructure:"uploads"`
+	Storage   StorageConfig                  `mapstructure:"storage"`
+	Metrics   MetricsConfig                  `mapstructure:"metrics"`
+	Profiling ProfilingConfig                `mapstructure:"profiling"`
+	OAuth     map[string]OAuthProviderConfig `mapstructure:"oauth"`
 }
 
 type AppConfig struct {
@@ -19,20 +29,82 @@ type AppConfig struct {
 }
 
 type DatabaseConfig struct {
-	Host        string `mapstructure:"host"`
-	Port        int    `mapstructure:"port"`
-	Name        string `mapstructure:"name"`
-	User        string `mapstructure:"user"`
-	Password    string `mapstructure:"password"`
-	Pool        int    `mapstructure:"pool"`
-	SSLMode     string `mapstructure:"ssl_mode"`
-	SlowQueryMs int    `mapstructure:"slow_query_ms"`
+	Host         string      `mapstructure:"host"`
+	Port         int         `mapstructure:"port"`
+	Name         string      `mapstructure:"name"`
+	User         string      `mapstructure:"user"`
+	Password     string      `mapstructure:"password"`
+	Pool         int         `mapstructure:"pool"`
+	SSLMode      string      `mapstructure:"ssl_mode"`
+	SlowQueryMs  int         `mapstructure:"slow_query_ms"`
+	ConnectRetry RetryConfig `mapstructure:"connect_retry"`
+	// Connection pool tuning. Durations take Go syntax ("30s", "5m").
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`     // Default pool/2; negative keeps none idle
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`  // Default 1h; negative never expires
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"` // Default unlimited
+	// Replicas receive read queries; writes always go to this database.
+	// Unset connection fields are inherited from the primary.
+	Replicas []DatabaseConfig `mapstructure:"replicas"`
+}
+
+// IdleConns returns the maximum number of idle connections to keep.
+func (c DatabaseConfig) IdleConns() int {
+	if c.MaxIdleConns == 0 {
+		return c.Pool / 2
+	}
+	return c.MaxIdleConns
+}
+
+// ConnLifetime returns how long a connection may be reused.
+func (c DatabaseConfig) ConnLifetime() time.Duration {
+	if c.ConnMaxLifetime == 0 {
+		return time.Hour
+	}
+	return c.ConnMaxLifetime
 }
 
 type RedisConfig struct {
-	URL  string `mapstructure:"url"`
-	Pool int    `mapstructure:"pool"`
-	DB   int    `mapstructure:"db"`
+	URL          string      `mapstructure:"url"`
+	Pool         int         `mapstructure:"pool"`
+	DB           int         `mapstructure:"db"`
+	ConnectRetry RetryConfig `mapstructure:"connect_retry"`
+}
+
+// RetryConfig controls connection retries with exponential backoff.
+type RetryConfig struct {
+	MaxAttempts   int `mapstructure:"max_attempts"`    // Default 3
+	InitialWaitMs int `mapstructure:"initial_wait_ms"` // Wait after the first failure, default 500
+	MaxWaitMs     int `mapstructure:"max_wait_ms"`     // Cap on the wait between attempts, default 5000
+}
+
+// Attempts returns the total number of connection attempts.
+func (r RetryConfig) Attempts() int {
+	if r.MaxAttempts <= 0 {
+		return 3
+	}
+	return r.MaxAttempts
+}
+
+// Backoff returns the wait after the given (1-indexed) failed attempt:
+// InitialWaitMs doubling each time, capped at MaxWaitMs, then jittered to
+// between half and all of that so replicas restarting together don't retry
+// in lockstep.
+func (r RetryConfig) Backoff(attempt int) time.Duration {
+	maxWait := time.Duration(r.MaxWaitMs) * time.Millisecond
+	if maxWait <= 0 {
+		maxWait = 5 * time.Second
+	}
+	wait := time.Duration(r.InitialWaitMs) * time.Millisecond
+	if wait <= 0 {
+		wait = 500 * time.Millisecond
+	}
+	for i := 1; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait/2 + rand.N(wait/2+1)
 }
 
 type SessionConfig struct {
@@ -61,3 +133,68 @@ type CacheConfig struct {
 	TTL    int    `mapstructure:"ttl"`
 	Prefix string `mapstructure:"prefix"`
 }
+
+type UploadConfig struct {
+	MaxSize   int64 `mapstructure:"max_size"`   // Max total request body size in bytes
+	MaxMemory int64 `mapstructure:"max_memory"` // Bytes buffered in memory before spilling to os.TempDir
+}
+
+// StorageConfig selects where uploaded files are stored (see the storage package).
+type StorageConfig struct {
+	Service string `mapstructure:"service"` // local (default) or s3
+
+	// Local disk
+	Root      string `mapstructure:"root"`       // Default public/uploads
+	URLPrefix string `mapstructure:"url_prefix"` // Default /uploads
+
+	// S3 and S3-compatible services
+	Bucket          string `mapstructure:"bucket"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PathStyle       bool   `mapstructure:"path_style"`
+	PublicURL       string `mapstructure:"public_url"`
+}
+
+// MetricsConfig controls the Prometheus endpoint.
+type MetricsConfig struct {
+	Enabled *bool  `mapstructure:"enabled"` // Default true
+	Path    string `mapstructure:"path"`    // Default /metrics
+	// Addr serves metrics on a separate listener (e.g. "127.0.0.1:9090")
+	// instead of the app's router, so they can stay off the public port.
+	Addr      string          `mapstructure:"addr"`
+	BasicAuth BasicAuthConfig `mapstructure:"basic_auth"`
+}
+
+// IsEnabled reports whether the metrics endpoint is served.
+func (c MetricsConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// ProfilingConfig controls the net/http/pprof endpoints under /debug/pprof.
+// They are off unless enabled, and outside development they also need basic
+// auth.
+type ProfilingConfig struct {
+	Enabled   bool            `mapstructure:"enabled"`
+	BasicAuth BasicAuthConfig `mapstructure:"basic_auth"`
+}
+
+// BasicAuthConfig holds HTTP Basic credentials. Without a username, no
+// authentication is required.
+type BasicAuthConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// OAuthProviderConfig configures an OAuth2 / OIDC login provider. The URLs
+// are only needed for providers without built-in defaults (google, github).
+type OAuthProviderConfig struct {
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+	AuthURL      string   `mapstructure:"auth_url"`
+	TokenURL     string   `mapstructure:"token_url"`
+	UserInfoURL  string   `mapstructure:"userinfo_url"`
+}
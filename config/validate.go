@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError lists every problem Validate found.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the config for values the app can't run with. Production
+// additionally requires a secret key and database connection settings. All
+// problems are reported together in a *ValidationError.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.App.Port <= 0 || c.App.Port > 65535 {
+		add("app.port must be between 1 and 65535, got %d", c.App.Port)
+	}
+	if c.Database.Pool < 0 {
+		add("database.pool must not be negative, got %d", c.Database.Pool)
+	}
+
+	switch c.Sessions.Store {
+	case "", "cookie":
+	case "redis":
+		if c.Redis.URL == "" {
+			add("redis.url is required when sessions.store is redis")
+		}
+	default:
+		add("sessions.store must be cookie or redis, got %q", c.Sessions.Store)
+	}
+
+	switch c.Storage.Service {
+	case "", "local":
+	case "s3":
+		if c.Storage.Bucket == "" {
+			add("storage.bucket is required when storage.service is s3")
+		}
+	default:
+		add("storage.service must be local or s3, got %q", c.Storage.Service)
+	}
+
+	if c.Metrics.Path != "" && !strings.HasPrefix(c.Metrics.Path, "/") {
+		add("metrics.path must start with /, got %q", c.Metrics.Path)
+	}
+	if c.Metrics.BasicAuth.Username != "" && c.Metrics.BasicAuth.Password == "" {
+		add("metrics.basic_auth.password is required when a username is set")
+	}
+
+	if c.Profiling.BasicAuth.Username != "" && c.Profiling.BasicAuth.Password == "" {
+		add("profiling.basic_auth.password is required when a username is set")
+	}
+	if c.Profiling.Enabled && c.Profiling.BasicAuth.Username == "" && c.App.Env != "development" {
+		add("profiling.basic_auth is required to enable profiling outside development")
+	}
+
+	if c.App.Env == "production" {
+		if c.App.SecretKeyBase == "" {
+			add("app.secret_key_base is required in production")
+		} else if len(c.App.SecretKeyBase) < 32 {
+			add("app.secret_key_base must be at least 32 characters in production")
+		}
+		if c.Database.Host == "" {
+			add("database.host is required in production")
+		}
+		if c.Database.Name == "" {
+			add("database.name is required in production")
+		}
+		if c.Database.User == "" {
+			add("database.user is required in production")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
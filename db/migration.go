@@ -10,25 +10,28 @@ import (
 
 // Migrate runs all pending migrations.
 func Migrate(db *gorm.DB, dir string) error {
-	sqlDB, err := db.DB()
+	sqlDB, err := gooseDB(db)
 	if err != nil {
 		return err
 	}
-	if err := goose.SetDialect("postgres"); err != nil {
+	return goose.Up(sqlDB, dir)
+}
+
+// MigrateTo runs pending migrations up to and including version.
+func MigrateTo(db *gorm.DB, dir string, version int64) error {
+	sqlDB, err := gooseDB(db)
+	if err != nil {
 		return err
 	}
-	return goose.Up(sqlDB, dir)
+	return goose.UpTo(sqlDB, dir, version)
 }
 
 // Rollback rolls back migrations.
 func Rollback(db *gorm.DB, dir string, steps int) error {
-	sqlDB, err := db.DB()
+	sqlDB, err := gooseDB(db)
 	if err != nil {
 		return err
 	}
-	if err := goose.SetDialect("postgres"); err != nil {
-		return err
-	}
 	if steps <= 0 {
 		steps = 1
 	}
@@ -40,16 +43,45 @@ func Rollback(db *gorm.DB, dir string, steps int) error {
 	return nil
 }
 
+// RollbackTo rolls back every migration newer than version, leaving version
+// applied. A version of 0 rolls back everything.
+func RollbackTo(db *gorm.DB, dir string, version int64) error {
+	sqlDB, err := gooseDB(db)
+	if err != nil {
+		return err
+	}
+	return goose.DownTo(sqlDB, dir, version)
+}
+
+// Redo rolls back the most recent migration and runs it again, for iterating
+// on a migration that is still being written.
+func Redo(db *gorm.DB, dir string) error {
+	sqlDB, err := gooseDB(db)
+	if err != nil {
+		return err
+	}
+	return goose.Redo(sqlDB, dir)
+}
+
 // MigrationStatus prints the migration status.
 func MigrationStatus(db *gorm.DB, dir string) error {
-	sqlDB, err := db.DB()
+	sqlDB, err := gooseDB(db)
 	if err != nil {
 		return err
 	}
+	return goose.Status(sqlDB, dir)
+}
+
+// gooseDB returns db's underlying *sql.DB with goose set up for Postgres.
+func gooseDB(db *gorm.DB) (*sql.DB, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
 	if err := goose.SetDialect("postgres"); err != nil {
-		return err
+		return nil, err
 	}
-	return goose.Status(sqlDB, dir)
+	return sqlDB, nil
 }
 
 // CreateDB creates a database by connecting to the 'postgres' default DB first.
@@ -17,10 +17,34 @@ import (
 var DB *gorm.DB
 
 // Connect establishes a PostgreSQL connection using GORM + pgx.
+// Failed attempts are retried with exponential backoff per cfg.ConnectRetry,
+// so the app survives the database coming up a few seconds after it.
+// If cfg.Replicas is set, reads are routed to them (see ConnectWithReplicas).
+// App.Boot connects with it too, so DB is set for apps that don't call it.
 func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
-		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode)
+	attempts := cfg.ConnectRetry.Attempts()
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		db, err := open(cfg)
+		if err == nil {
+			if err := useReplicas(db, cfg); err != nil {
+				return nil, err
+			}
+			DB = db
+			return db, nil
+		}
+		lastErr = err
+		if attempt < attempts {
+			wait := cfg.ConnectRetry.Backoff(attempt)
+			log.Printf("[Gails] WARN: PostgreSQL not ready (attempt %d/%d), retrying in %s — %v", attempt, attempts, wait, err)
+			time.Sleep(wait)
+		}
+	}
+	return nil, lastErr
+}
 
+// open makes a single connection attempt.
+func open(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	env := os.Getenv("APP_ENV")
 	if env == "" {
 		env = "development"
@@ -49,7 +73,7 @@ func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		},
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(dsn(cfg)), &gorm.Config{
 		Logger: gormLogger,
 	})
 	if err != nil {
@@ -61,9 +85,10 @@ func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	sqlDB.SetMaxIdleConns(cfg.Pool / 2)
+	sqlDB.SetMaxIdleConns(cfg.IdleConns())
 	sqlDB.SetMaxOpenConns(cfg.Pool)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxLifetime(cfg.ConnLifetime())
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Ping and fail fast
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -72,10 +97,24 @@ func Connect(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("[Gails] ERROR: Cannot connect to PostgreSQL at %s:%d — %v", cfg.Host, cfg.Port, err)
 	}
 
-	DB = db
 	return db, nil
 }
 
+// dsn builds the pgx connection string for cfg.
+func dsn(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode)
+}
+
+// ConnectWithRetry is Connect with the retry policy given directly: up to
+// maxAttempts tries, waiting backoff after the first failure and doubling
+// (with jitter) after each one, up to cfg.ConnectRetry's cap.
+func ConnectWithRetry(cfg config.DatabaseConfig, maxAttempts int, backoff time.Duration) (*gorm.DB, error) {
+	cfg.ConnectRetry.MaxAttempts = maxAttempts
+	cfg.ConnectRetry.InitialWaitMs = int(backoff.Milliseconds())
+	return Connect(cfg)
+}
+
 // MustConnect connects or panics.
 func MustConnect(cfg config.DatabaseConfig) *gorm.DB {
 	db, err := Connect(cfg)
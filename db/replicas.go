@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/shaurya/gails/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ConnectWithReplicas connects to primary and registers replicas as read
+// replicas: queries (Find, First, Count, Pluck...) are spread across them at
+// random, while Create/Save/Update/Delete, raw Exec and transactions use the
+// primary. Use orm.QueryBuilder.UsePrimary to read your own writes.
+//
+// Equivalent to setting primary.Replicas and calling Connect, which is what
+// the `replicas:` list under `database:` in app.yaml does.
+func ConnectWithReplicas(primary config.DatabaseConfig, replicas []config.DatabaseConfig) (*gorm.DB, error) {
+	primary.Replicas = replicas
+	return Connect(primary)
+}
+
+// useReplicas registers the dbresolver plugin for cfg.Replicas, if any.
+func useReplicas(db *gorm.DB, cfg config.DatabaseConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	dialectors := make([]gorm.Dialector, len(cfg.Replicas))
+	for i, r := range cfg.Replicas {
+		dialectors[i] = postgres.Open(dsn(replicaConfig(cfg, r)))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxIdleConns(cfg.IdleConns()).
+		SetMaxOpenConns(cfg.Pool).
+		SetConnMaxLifetime(cfg.ConnLifetime()).
+		SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("[Gails] ERROR: Cannot configure read replicas — %v", err)
+	}
+	return nil
+}
+
+// replicaConfig fills r's unset connection fields from the primary, so a
+// replica usually only needs its host.
+func replicaConfig(primary, r config.DatabaseConfig) config.DatabaseConfig {
+	if r.Host == "" {
+		r.Host = primary.Host
+	}
+	if r.Port == 0 {
+		r.Port = primary.Port
+	}
+	if r.Name == "" {
+		r.Name = primary.Name
+	}
+	if r.User == "" {
+		r.User = primary.User
+	}
+	if r.Password == "" {
+		r.Password = primary.Password
+	}
+	if r.SSLMode == "" {
+		r.SSLMode = primary.SSLMode
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/shaurya/gails/config"
+)
+
+// SchemaFile is where DumpSchema writes and LoadSchema reads by default.
+const SchemaFile = "db/schema.sql"
+
+// DumpSchema writes the connected database's schema to path using pg_dump,
+// which must be on PATH. The goose version table's rows are included, so a
+// database loaded from the dump is already marked as fully migrated.
+func DumpSchema(cfg config.DatabaseConfig, path string) error {
+	schema, err := pgTool(cfg, "pg_dump", "--schema-only", "--no-owner", "--no-privileges")
+	if err != nil {
+		return fmt.Errorf("[Gails] ERROR: Cannot dump schema — %v", err)
+	}
+	versions, err := pgTool(cfg, "pg_dump", "--data-only", "--table=goose_db_version")
+	if err != nil {
+		return fmt.Errorf("[Gails] ERROR: Cannot dump migration versions — %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(schema, versions...), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("[Gails] Dumped schema to %s\n", path)
+	return nil
+}
+
+// LoadSchema loads a DumpSchema file into the database with psql, which must
+// be on PATH. Use it on an empty database in place of running every migration.
+func LoadSchema(cfg config.DatabaseConfig, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("[Gails] ERROR: Cannot load schema — %v", err)
+	}
+	if _, err := pgTool(cfg, "psql", "--quiet", "-v", "ON_ERROR_STOP=1", "--file="+path); err != nil {
+		return fmt.Errorf("[Gails] ERROR: Cannot load schema %s — %v", path, err)
+	}
+	fmt.Printf("[Gails] Loaded schema from %s\n", path)
+	return nil
+}
+
+// pgTool runs a PostgreSQL client tool against cfg's database and returns its
+// stdout. The password goes through PGPASSWORD so it never shows up in ps.
+func pgTool(cfg config.DatabaseConfig, name string, args ...string) ([]byte, error) {
+	args = append([]string{
+		"--host=" + cfg.Host,
+		"--port=" + strconv.Itoa(cfg.Port),
+		"--username=" + cfg.User,
+		"--dbname=" + cfg.Name,
+	}, args...)
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+cfg.Password)
+	if cfg.SSLMode != "" {
+		cmd.Env = append(cmd.Env, "PGSSLMODE="+cfg.SSLMode)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
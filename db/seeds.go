@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/shaurya/gails/orm"
 	"gorm.io/gorm"
 )
 
@@ -96,13 +97,21 @@ func (f *Faker) Bool() bool {
 	return f.r.Intn(2) == 1
 }
 
-// Fake creates N fake records using a builder function and inserts them into the database.
+// fakeBatchSize is how many fake records Fake builds and inserts per INSERT.
+const fakeBatchSize = 1000
+
+// Fake creates N fake records using a builder function and inserts them into
+// the database in batches.
 func Fake[T any](db *gorm.DB, count int, builder func(f *Faker) T) error {
 	faker := NewFaker()
+	batch := make([]T, 0, min(count, fakeBatchSize))
 	for i := 0; i < count; i++ {
-		record := builder(faker)
-		if err := db.Create(&record).Error; err != nil {
-			return fmt.Errorf("failed to create fake record %d: %w", i+1, err)
+		batch = append(batch, builder(faker))
+		if len(batch) == fakeBatchSize || i == count-1 {
+			if err := orm.Query[T](db).CreateBatch(batch, fakeBatchSize); err != nil {
+				return fmt.Errorf("failed to create fake records %d-%d: %w", i+2-len(batch), i+1, err)
+			}
+			batch = batch[:0]
 		}
 	}
 	fmt.Printf("[Gails] Created %d fake records\n", count)
@@ -0,0 +1,173 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type apiVersionKey struct{}
+
+// apiVersions tracks the API versions registered on a router tree so that
+// unversioned requests (/api/users) can be routed to a concrete version.
+type apiVersions struct {
+	mu         sync.RWMutex
+	bases      map[string][]string // "/api" base path -> versions, in registration order
+	defaults   map[string]string
+	deprecated map[string]time.Time // "/api/v1" -> sunset (zero if none)
+}
+
+func newAPIVersions() *apiVersions {
+	return &apiVersions{
+		bases:      make(map[string][]string),
+		defaults:   make(map[string]string),
+		deprecated: make(map[string]time.Time),
+	}
+}
+
+// APIVersion groups routes under /api/{version}.
+//
+//	r.APIVersion("v1", func(r *framework.Router) {
+//		r.Resources("users", &UsersController{})
+//	})
+//
+// Requests to /api/... without a version segment are routed by the Accept
+// header (application/vnd.myapp.v2+json), falling back to the default version:
+// the first one registered, unless set with DefaultAPIVersion.
+// Handlers can read the resolved version with Context.APIVersion.
+func (r *Router) APIVersion(version string, fn func(r *Router)) {
+	r.api.register(r.prefix+"/api", version)
+	r.namespace("/api/"+version, fn, withAPIVersion(r.api, r.prefix+"/api/"+version, version))
+}
+
+// DeprecateAPIVersion marks a version deprecated: every response it serves
+// gets a "Deprecation: true" header and, unless sunset is zero, a Sunset
+// header announcing when it will be removed.
+//
+//	r.DeprecateAPIVersion("v1", time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC))
+//
+// It panics if version wasn't registered with APIVersion on this router first,
+// since the headers would otherwise silently never be sent.
+func (r *Router) DeprecateAPIVersion(version string, sunset time.Time) {
+	r.api.mu.Lock()
+	defer r.api.mu.Unlock()
+	if !slices.Contains(r.api.bases[r.prefix+"/api"], version) {
+		panic(fmt.Sprintf("framework: DeprecateAPIVersion(%q): version not registered with APIVersion", version))
+	}
+	r.api.deprecated[r.prefix+"/api/"+version] = sunset
+}
+
+// DefaultAPIVersion sets the version used for unversioned /api requests that
+// don't name a version in their Accept header.
+func (r *Router) DefaultAPIVersion(version string) {
+	r.api.mu.Lock()
+	defer r.api.mu.Unlock()
+	r.api.defaults[r.prefix+"/api"] = version
+}
+
+// APIVersion returns the API version that served the request, or "" outside APIVersion groups.
+func (c *Context) APIVersion() string {
+	v, _ := c.Request.Context().Value(apiVersionKey{}).(string)
+	return v
+}
+
+// Deprecated marks the response as coming from a deprecated endpoint, for
+// deprecating single actions rather than a whole version (see
+// DeprecateAPIVersion). A zero sunset omits the Sunset header.
+func (c *Context) Deprecated(sunset time.Time) {
+	setDeprecationHeaders(c.Response.Header(), sunset)
+}
+
+func setDeprecationHeaders(h http.Header, sunset time.Time) {
+	h.Set("Deprecation", "true")
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// withAPIVersion tags requests with their API version and adds deprecation
+// headers if the version at path has been deprecated.
+func withAPIVersion(a *apiVersions, path, version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.mu.RLock()
+			sunset, deprecated := a.deprecated[path]
+			a.mu.RUnlock()
+			if deprecated {
+				setDeprecationHeaders(w.Header(), sunset)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+func (a *apiVersions) register(base, version string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if slices.Contains(a.bases[base], version) {
+		return
+	}
+	a.bases[base] = append(a.bases[base], version)
+	if a.defaults[base] == "" {
+		a.defaults[base] = version
+	}
+}
+
+// negotiate rewrites an unversioned API path to the negotiated version.
+// Paths that already match a route, such as an /api/v1 namespace registered
+// without APIVersion, are left alone.
+func (a *apiVersions) negotiate(req *http.Request, matches func(path string) bool) *http.Request {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.bases) == 0 {
+		return req
+	}
+
+	path := req.URL.Path
+	for base, versions := range a.bases {
+		if path != base && !strings.HasPrefix(path, base+"/") {
+			continue
+		}
+		rest := strings.TrimPrefix(path, base)
+		segment := strings.SplitN(strings.TrimPrefix(rest, "/"), "/", 2)[0]
+		if slices.Contains(versions, segment) || matches(path) {
+			return req
+		}
+
+		version := acceptedVersion(req.Header.Get("Accept"), versions)
+		if version == "" {
+			version = a.defaults[base]
+		}
+		if version == "" {
+			return req
+		}
+
+		r2 := req.Clone(req.Context())
+		r2.URL.Path = base + "/" + version + rest
+		r2.URL.RawPath = ""
+		return r2
+	}
+	return req
+}
+
+// acceptedVersion extracts a registered version from vendor media types such
+// as application/vnd.myapp.v2+json.
+func acceptedVersion(accept string, versions []string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+		_, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || !strings.HasPrefix(subtype, "vnd.") {
+			continue
+		}
+		subtype, _, _ = strings.Cut(subtype, "+")
+		version := subtype[strings.LastIndex(subtype, ".")+1:]
+		if slices.Contains(versions, version) {
+			return version
+		}
+	}
+	return ""
+}
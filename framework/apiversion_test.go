@@ -0,0 +1,68 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIVersionLeavesNamespacedVersionsAlone(t *testing.T) {
+	r := NewRouter()
+	r.Namespace("/api/v1", func(r *Router) {
+		r.GET("/status", func(c *Context) error { return c.JSON(http.StatusOK, H{"version": "v1"}) })
+	})
+	r.APIVersion("v2", func(r *Router) {
+		r.GET("/status", func(c *Context) error { return c.JSON(http.StatusOK, H{"version": c.APIVersion()}) })
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/status", `{"version":"v1"}`},
+		{"/api/v2/status", `{"version":"v2"}`},
+		{"/api/status", `{"version":"v2"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d, want 200", tt.path, w.Code)
+			continue
+		}
+		if got := w.Body.String(); got != tt.want+"\n" && got != tt.want {
+			t.Errorf("GET %s: body %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDeprecateAPIVersionPanicsForUnknownVersion(t *testing.T) {
+	r := NewRouter()
+	r.APIVersion("v2", func(r *Router) {})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("DeprecateAPIVersion(\"v1\") without APIVersion(\"v1\") did not panic")
+		}
+	}()
+	r.DeprecateAPIVersion("v1", time.Time{})
+}
+
+func TestDeprecateAPIVersionSetsHeaders(t *testing.T) {
+	r := NewRouter()
+	r.APIVersion("v1", func(r *Router) {
+		r.GET("/status", func(c *Context) error { return c.JSON(http.StatusOK, H{}) })
+	})
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	r.DeprecateAPIVersion("v1", sunset)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	if got := w.Header().Get("Deprecation"); got != "true" {
+		t.Errorf("Deprecation = %q, want %q", got, "true")
+	}
+	if got, want := w.Header().Get("Sunset"), sunset.Format(http.TimeFormat); got != want {
+		t.Errorf("Sunset = %q, want %q", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/shaurya/gails/config"
 	"github.com/shaurya/gails/framework/assets"
 	"github.com/shaurya/gails/framework/i18n"
+	"github.com/shaurya/gails/storage"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -23,18 +25,33 @@ type App struct {
 	DB       *gorm.DB
 	Redis    *redis.Client
 	Cache    cache.Cache
+	Storage  storage.Storage
 	Config   *config.Config
 	Router   *Router
 	Renderer *Renderer
 	Plugins  []Plugin
 	Log      *zap.Logger
+
+	mu            sync.Mutex
+	middleware    []func(http.Handler) http.Handler
+	routeFns      []func(r *Router)
+	bootedPlugins []Plugin
+	routesOnce    sync.Once
+	routesMounted bool
+	shutdownHooks []func(ctx context.Context) error
+	configErr     error // why LoadConfig failed in New, if it did
 }
 
+// DrainTimeout bounds graceful shutdown: in-flight requests, then the
+// OnShutdown hooks, must finish within it.
+const DrainTimeout = 30 * time.Second
+
 // New creates a new Gails application instance.
 func New() *App {
 	cfg, err := LoadConfig()
 	if err != nil {
-		// In case the config file is not found, use defaults
+		// In case the config file is not found, use defaults. Boot reports
+		// the error, and refuses to start in production.
 		cfg = &config.Config{
 			App: config.AppConfig{
 				Name: "MyApp",
@@ -57,12 +74,14 @@ func New() *App {
 	router := NewRouter()
 
 	app := &App{
-		Config:  cfg,
-		Router:  router,
-		Plugins: make([]Plugin, 0),
+		Config:    cfg,
+		Router:    router,
+		Plugins:   make([]Plugin, 0),
+		configErr: err,
 	}
 
 	router.app = app
+	app.OnShutdown(stopMemoryLimiters)
 
 	return app
 }
@@ -72,20 +91,76 @@ func (a *App) Register(p Plugin) {
 	a.Plugins = append(a.Plugins, p)
 }
 
+// Use queues application middleware. Middleware runs in a fixed order for
+// every request: framework defaults (RequestID, Tracing, Logger, Metrics,
+// Recovery, SecureHeaders), then App.Use middleware in the order added, then routes.
+// Call it before Run/Boot.
+func (a *App) Use(mw ...func(http.Handler) http.Handler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.middleware = append(a.middleware, mw...)
+}
+
+// OnShutdown registers fn to run when Run shuts down, after the HTTP server
+// has stopped accepting requests and before the database and Redis are
+// closed. Hooks run concurrently and share the drain window: ctx expires
+// when DrainTimeout runs out.
+//
+//	app.OnShutdown(hub.Shutdown)
+//	app.OnShutdown(worker.Shutdown)
+func (a *App) OnShutdown(fn func(ctx context.Context) error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.shutdownHooks = append(a.shutdownHooks, fn)
+}
+
 // Routes configures the application routes using a callback function.
+// Routes are mounted during Boot, after all middleware has been registered.
 func (a *App) Routes(fn func(r *Router)) {
+	a.mu.Lock()
+	if !a.routesMounted {
+		a.routeFns = append(a.routeFns, fn)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
 	fn(a.Router)
 }
 
+// mountRoutes registers plugin and application routes exactly once. Chi
+// rejects middleware added after the first route, so this must run after
+// all Use calls; it also runs on the first request if the app never booted.
+func (a *App) mountRoutes() {
+	a.routesOnce.Do(func() {
+		a.mu.Lock()
+		fns := a.routeFns
+		a.routeFns = nil
+		a.routesMounted = true
+		a.mu.Unlock()
+
+		for _, p := range a.bootedPlugins {
+			p.Routes(a.Router)
+		}
+		for _, fn := range fns {
+			fn(a.Router)
+		}
+	})
+}
+
 // Boot initializes all application subsystems in order.
 func (a *App) Boot() {
 	// 1. Load config (already done in New())
 
-	// 2. Initialize logger
-	InitLogger()
+	// 2. Initialize logger, unless the app brought its own (SetLogger)
+	if !loggerSet.Load() {
+		InitLogger()
+	}
 	a.Log = Log
 	Log.Info("Booting Gails...")
 
+	// Check the config before anything relies on it
+	a.validateConfig()
+
 	// 3. Initialize i18n
 	if err := i18n.Init("config/locales"); err != nil {
 		Log.Warn("Failed to initialize i18n", zap.Error(err))
@@ -97,27 +172,89 @@ func (a *App) Boot() {
 	}
 
 	// 5. Initialize session store
-	InitSessionStore(a.Config.App.SecretKeyBase)
+	if err := InitSessionStore(a.Config); err != nil {
+		Log.Error("Failed to initialize session store, falling back to cookies", zap.Error(err))
+		InitSessionStore(&config.Config{App: a.Config.App, Sessions: config.SessionConfig{TTL: a.Config.Sessions.TTL}})
+	}
+
+	// 6. Set up file storage and the upload temp dir
+	if a.Storage == nil {
+		store, err := storage.New(a.Config.Storage)
+		if err != nil {
+			Log.Error("Failed to initialize storage", zap.Error(err))
+		} else {
+			a.Storage = store
+		}
+	}
+
+	// 7. Connect to PostgreSQL and Redis, and set up the cache
+	a.connectServices()
+
+	// 8. Instrument database queries (metrics, slow-query breadcrumbs)
+	if a.DB != nil {
+		InstrumentDB(a.DB, a.Config.Database.SlowQueryMs)
+	}
 
-	// 6. Boot all registered plugins
+	// 9. Boot all registered plugins
 	a.bootPlugins()
 
-	// 7. Register default middleware
+	// 10. Register default middleware
 	a.Router.Use(RequestID())
+	a.Router.Use(Tracing())
 	a.Router.Use(Logger())
+	a.Router.Use(Metrics())
 	a.Router.Use(Recovery())
-	a.Router.Mux.Use(SecureHeaders)
+	a.Router.Use(MethodOverride())
+	a.Router.Use(SecureHeaders)
+	if a.Config.App.Env == "development" && a.DB != nil {
+		RegisterQueryCounter(a.DB)
+		a.Router.Use(QueryCounter(QueryCounterConfig{Header: true}))
+	}
 
-	// 8. Initialize renderer
+	// 11. Register application middleware (App.Use)
+	a.mu.Lock()
+	for _, mw := range a.middleware {
+		a.Router.Use(mw)
+	}
+	a.mu.Unlock()
+
+	// 12. Initialize renderer
 	a.Renderer = NewRenderer(a.Config)
 
-	// 9. Mount metrics endpoint
-	a.Router.Mux.Handle("/metrics", MetricsHandler())
-	a.Router.addRoute("GET", "/metrics", "Prometheus")
+	// 13. Mount plugin and application routes
+	a.mountRoutes()
+
+	// 14. Mount metrics and profiling endpoints
+	a.mountMetrics()
+	a.mountProfiler()
+
+	// 15. Serve files kept on local disk
+	if disk, ok := a.Storage.(*storage.LocalDisk); ok {
+		a.Router.Mux.Handle(disk.URLPrefix+"/*", http.StripPrefix(disk.URLPrefix, disk))
+		a.Router.addRoute("GET", disk.URLPrefix+"/*", "Storage")
+	}
 
 	Log.Info("Gails booted successfully")
 }
 
+// validateConfig reports config that failed to load or is invalid. In
+// production it stops the process rather than boot a broken app.
+func (a *App) validateConfig() {
+	production := a.Config.App.Env == "production"
+	if a.configErr != nil {
+		if production {
+			Log.Fatal("Failed to load config", zap.Error(a.configErr))
+		}
+		Log.Warn("Failed to load config, using defaults", zap.Error(a.configErr))
+	}
+	if err := a.Config.Validate(); err != nil {
+		if production {
+			Log.Fatal("Invalid config", zap.Error(err))
+		}
+		Log.Warn("Invalid config", zap.Error(err))
+	}
+}
+
 // bootPlugins initializes all registered plugins.
 func (a *App) bootPlugins() {
 	for _, p := range a.Plugins {
@@ -129,8 +266,8 @@ func (a *App) bootPlugins() {
 			continue
 		}
 
-		// Register plugin routes
-		p.Routes(a.Router)
+		// Plugin routes are mounted with the application routes
+		a.bootedPlugins = append(a.bootedPlugins, p)
 	}
 }
 
@@ -145,7 +282,7 @@ func (a *App) Run() {
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
-		Handler: a.Router.Mux,
+		Handler: a.Router,
 	}
 
 	// Boot banner
@@ -171,6 +308,7 @@ func (a *App) Run() {
 			Log.Fatal("Server failed", zap.Error(err))
 		}
 	}()
+	metricsServer := a.startMetricsServer()
 
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -179,13 +317,17 @@ func (a *App) Run() {
 
 	Log.Info("Shutting down server...")
 
-	drainTimeout := 30 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), DrainTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
 		Log.Error("Shutdown failed", zap.Error(err))
 	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(ctx)
+	}
+	a.runShutdownHooks(ctx)
+	a.shutdownPlugins()
 
 	// Close DB pool
 	if a.DB != nil {
@@ -201,3 +343,49 @@ func (a *App) Run() {
 
 	Log.Info("Gails stopped gracefully")
 }
+
+// shutdownPlugins calls Shutdown on booted plugins that implement
+// PluginWithShutdown, in reverse boot order.
+func (a *App) shutdownPlugins() {
+	for i := len(a.bootedPlugins) - 1; i >= 0; i-- {
+		p, ok := a.bootedPlugins[i].(PluginWithShutdown)
+		if !ok {
+			continue
+		}
+		if err := p.Shutdown(a); err != nil {
+			Log.Error("Failed to shut down plugin",
+				zap.String("name", p.Name()),
+				zap.Error(err))
+		}
+	}
+}
+
+// runShutdownHooks runs the OnShutdown hooks concurrently and waits for them,
+// or for ctx to expire.
+func (a *App) runShutdownHooks(ctx context.Context) {
+	a.mu.Lock()
+	hooks := append([]func(context.Context) error(nil), a.shutdownHooks...)
+	a.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		wg.Add(1)
+		go func(hook func(context.Context) error) {
+			defer wg.Done()
+			if err := hook(ctx); err != nil {
+				Log.Error("Shutdown hook failed", zap.Error(err))
+			}
+		}(hook)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		Log.Warn("Shutdown hooks did not finish within the drain timeout")
+	}
+}
@@ -0,0 +1,67 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	h := BasicAuth("admin", "s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		user, pass string
+		noAuth     bool
+		want       int
+	}{
+		{name: "valid", user: "admin", pass: "s3cret", want: http.StatusNoContent},
+		{name: "missing", noAuth: true, want: http.StatusUnauthorized},
+		{name: "wrong password", user: "admin", pass: "nope", want: http.StatusUnauthorized},
+		{name: "wrong user", user: "root", pass: "s3cret", want: http.StatusUnauthorized},
+		{name: "password prefix", user: "admin", pass: "s3cre", want: http.StatusUnauthorized},
+		{name: "password suffix", user: "admin", pass: "s3cret!", want: http.StatusUnauthorized},
+		{name: "empty", user: "", pass: "", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if !tt.noAuth {
+				r.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if tt.want == http.StatusUnauthorized && challenge != `Basic realm="Gails", charset="UTF-8"` {
+				t.Errorf("WWW-Authenticate = %q", challenge)
+			}
+			if tt.want != http.StatusUnauthorized && challenge != "" {
+				t.Errorf("authorized response sent WWW-Authenticate %q", challenge)
+			}
+		})
+	}
+}
+
+func TestSecureCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"s3cret", "s3cret", true},
+		{"", "", true},
+		{"s3cret", "s3cre", false},
+		{"s3cret", "s3cret\x00", false},
+		{"s3cret", "S3cret", false},
+	}
+	for _, tt := range tests {
+		if got := secureCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("secureCompare(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
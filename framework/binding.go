@@ -0,0 +1,374 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LenientNumbers can be implemented by a bind target to accept string-encoded
+// numbers ("id":"42") in every numeric field. To opt in a single field instead,
+// tag it with `bind:"lenient"`.
+type LenientNumbers interface {
+	LenientNumbers() bool
+}
+
+// decodeJSON unmarshals body into v. Numeric fields that opted into lenient
+// mode also accept their value as a JSON string; all other fields keep the
+// standard library's strict behavior.
+func decodeJSON(body []byte, v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	all := structIsLenient(v)
+	if t == nil || t.Kind() != reflect.Struct || !hasLenientFields(t, all, make(map[reflect.Type]bool)) {
+		return json.Unmarshal(body, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	coerceLenientNumbers(raw, t, all)
+
+	// json.Number re-encodes as its exact digits, so large integers survive the round trip.
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func structIsLenient(v any) bool {
+	l, ok := v.(LenientNumbers)
+	return ok && l.LenientNumbers()
+}
+
+// hasLenientFields reports whether t has any numeric field that accepts
+// strings, including in nested structs and slices of structs. seen stops
+// recursive types (Node{Next *Node}) from looping.
+func hasLenientFields(t reflect.Type, all bool, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		ft := derefType(f.Type)
+		if isNumericKind(ft.Kind()) && (all || isLenientField(f)) {
+			return true
+		}
+		if st, ok := nestedStruct(ft); ok && hasLenientFields(st, all, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedStruct returns the struct type bound from a JSON object (a struct
+// field) or array of objects (a slice or array of structs), if t is one.
+func nestedStruct(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = derefType(t.Elem())
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	return t, true
+}
+
+// coerceLenientNumbers rewrites string values of lenient numeric fields into json.Number.
+func coerceLenientNumbers(raw map[string]any, t reflect.Type, all bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs share the parent's JSON object.
+		ft := derefType(f.Type)
+		if f.Anonymous && ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			coerceLenientNumbers(raw, ft, all)
+			continue
+		}
+
+		key, val, ok := lookupJSONKey(raw, name)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case isNumericKind(ft.Kind()) && (all || isLenientField(f)):
+			if s, isStr := val.(string); isStr {
+				num := json.Number(strings.TrimSpace(s))
+				if _, err := num.Float64(); err == nil {
+					raw[key] = num
+				}
+			}
+		default:
+			st, ok := nestedStruct(ft)
+			if !ok {
+				continue
+			}
+			switch val := val.(type) {
+			case map[string]any:
+				coerceLenientNumbers(val, st, all)
+			case []any:
+				for _, elem := range val {
+					if sub, isMap := elem.(map[string]any); isMap {
+						coerceLenientNumbers(sub, st, all)
+					}
+				}
+			}
+		}
+	}
+}
+
+// lookupJSONKey finds a key the way encoding/json does: exact match first, then case-insensitive.
+func lookupJSONKey(raw map[string]any, name string) (string, any, bool) {
+	if v, ok := raw[name]; ok {
+		return name, v, true
+	}
+	for k, v := range raw {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func isLenientField(f reflect.StructField) bool {
+	for _, opt := range strings.Split(f.Tag.Get("bind"), ",") {
+		if strings.TrimSpace(opt) == "lenient" {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// bindValues copies url.Values into the struct pointed to by v, matching each
+// field by its `query` tag (falling back to the field name, case-insensitively)
+// and converting to the field's type. Conversion failures are collected per
+// parameter rather than aborting, so every bad value is reported at once.
+func bindValues(values url.Values, v any) (map[string][]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bind target must be a non-nil pointer to a struct, got %T", v)
+	}
+	errs := make(map[string][]string)
+	bindStruct(values, rv.Elem(), errs)
+	return errs, nil
+}
+
+func bindStruct(values url.Values, sv reflect.Value, errs map[string][]string) {
+	t := sv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := queryFieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		fv := sv.Field(i)
+		if f.Anonymous && derefType(f.Type).Kind() == reflect.Struct && f.Tag.Get("query") == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			bindStruct(values, fv, errs)
+			continue
+		}
+
+		raw, ok := lookupQueryKey(values, name)
+		if !ok {
+			continue
+		}
+		if err := setFieldFromStrings(fv, raw); err != nil {
+			errs[name] = append(errs[name], fmt.Sprintf("%s must be a valid %s", name, describeKind(derefType(f.Type))))
+		}
+	}
+}
+
+func queryFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("query"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// lookupQueryKey finds a parameter by exact name, then case-insensitively.
+func lookupQueryKey(values url.Values, name string) ([]string, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// setFieldFromStrings assigns raw to fv. Slices take every value ("?tag=a&tag=b"
+// or "?tag=a,b"); scalars take the first.
+func setFieldFromStrings(fv reflect.Value, raw []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		var items []string
+		for _, r := range raw {
+			items = append(items, strings.Split(r, ",")...)
+		}
+		slice := reflect.MakeSlice(fv.Type(), 0, len(items))
+		for _, item := range items {
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setFieldFromString(elem, item); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fv.Set(slice)
+		return nil
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return setFieldFromString(fv, raw[0])
+}
+
+func setFieldFromString(fv reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if fv.Kind() == reflect.Ptr {
+		if s == "" {
+			return nil
+		}
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setFieldFromString(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	// An empty parameter ("?page=") leaves non-string fields at their zero value.
+	if s == "" && fv.Kind() != reflect.String {
+		return nil
+	}
+
+	if fv.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				fv.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", s)
+	}
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		if s == "on" {
+			fv.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+func describeKind(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "time"
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "list of " + describeKind(derefType(t.Elem()))
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return "integer"
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		return "non-negative integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,189 @@
+package framework
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shaurya/gails/cache"
+)
+
+// StaleCheck sets ETag and Last-Modified headers derived from the resource's
+// UpdatedAt and reports whether the client's copy is stale. When the request's
+// If-None-Match / If-Modified-Since match, it writes 304 Not Modified and
+// returns false so the action can skip rendering (Rails' stale?).
+//
+//	if !ctx.StaleCheck(post) {
+//		return nil
+//	}
+//	return ctx.JSON(http.StatusOK, post)
+//
+// Resources may be a model, a pointer to one, or a slice of models.
+func (c *Context) StaleCheck(resource any) bool {
+	lastMod, ok := resourceLastModified(resource)
+	if !ok {
+		return true
+	}
+
+	etag := weakETag(resourceETagKey(resource, lastMod))
+	c.Response.Header().Set("ETag", etag)
+	c.Response.Header().Set("Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+
+	if c.isFresh(etag, lastMod) {
+		c.Status(http.StatusNotModified)
+		return false
+	}
+	return true
+}
+
+// JSONWithETag writes a JSON response with a weak ETag computed from the
+// encoded body. If the request's If-None-Match matches, it writes 304 Not
+// Modified with no body instead, so polling clients skip unchanged data.
+func (c *Context) JSONWithETag(status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n') // match JSON's Encoder output
+
+	etag := weakETag(string(body))
+	c.Response.Header().Set("ETag", etag)
+	if status == http.StatusOK && c.isFresh(etag, time.Time{}) {
+		return c.Status(http.StatusNotModified)
+	}
+
+	c.Response.Header().Set("Content-Type", "application/json")
+	c.Response.WriteHeader(status)
+	c.statusCode = status
+	c.written = true
+	_, err = c.Response.Write(body)
+	return err
+}
+
+// Fresh sets Last-Modified to lastMod and reports whether the client's copy
+// is still fresh, judged by If-Modified-Since (or If-None-Match against an
+// ETag already set on the response). When it is, Fresh writes 304 Not
+// Modified and the action should return without rendering.
+//
+//	if ctx.Fresh(post.UpdatedAt) {
+//		return nil
+//	}
+//	return ctx.Render("posts/show.html", framework.H{"Post": post})
+func (c *Context) Fresh(lastMod time.Time) bool {
+	if !lastMod.IsZero() {
+		c.Response.Header().Set("Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+	}
+	if c.isFresh(c.Response.Header().Get("ETag"), lastMod) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// isFresh evaluates the request's conditional headers against etag and lastMod.
+// If-None-Match takes precedence over If-Modified-Since (RFC 9110 §13.2.2).
+func (c *Context) isFresh(etag string, lastMod time.Time) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := c.Request.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatches(inm, etag)
+	}
+
+	if ims := c.Request.Header.Get("If-Modified-Since"); ims != "" && !lastMod.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastMod.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// etagMatches performs a weak comparison of etag against an If-None-Match list.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// weakETag hashes key into a weak ETag value.
+func weakETag(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return `W/"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// resourceLastModified returns the newest UpdatedAt of a model or slice of models.
+func resourceLastModified(resource any) (time.Time, bool) {
+	v := reflect.ValueOf(resource)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return time.Time{}, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return updatedAtOf(v)
+	case reflect.Slice, reflect.Array:
+		var newest time.Time
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if t, ok := updatedAtOf(elem); ok && t.After(newest) {
+				newest = t
+			}
+		}
+		return newest, !newest.IsZero()
+	}
+	return time.Time{}, false
+}
+
+func updatedAtOf(v reflect.Value) (time.Time, bool) {
+	if v.Kind() != reflect.Struct {
+		return time.Time{}, false
+	}
+	f := v.FieldByName("UpdatedAt")
+	if !f.IsValid() {
+		return time.Time{}, false
+	}
+	t, ok := f.Interface().(time.Time)
+	return t, ok && !t.IsZero()
+}
+
+// resourceETagKey builds the string hashed into a resource's ETag.
+func resourceETagKey(resource any, lastMod time.Time) string {
+	if _, ok := resource.(cache.CacheKeyer); ok {
+		return cache.ModelKey(resource)
+	}
+
+	v := reflect.ValueOf(resource)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return fmt.Sprintf("%s/%d-%d", v.Type(), v.Len(), lastMod.UnixNano())
+	}
+
+	var id any
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("ID"); f.IsValid() {
+			id = f.Interface()
+		}
+	}
+	return fmt.Sprintf("%s/%v-%d", v.Type(), id, lastMod.UnixNano())
+}
@@ -3,12 +3,23 @@ package framework
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/shaurya/gails/config"
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix prefixes the environment variables that override config keys.
+const EnvPrefix = "GAILS_"
+
+// LoadConfig reads config/app.yaml, merges config/environments/<APP_ENV>.yaml
+// over it, then applies environment variable overrides. Every key can be set
+// from the environment as GAILS_<KEY> or <KEY>, with dots as underscores:
+// database.password is GAILS_DATABASE_PASSWORD (or DATABASE_PASSWORD).
+// Lists and maps (database.replicas, queue.queues, oauth) can't be
+// overridden this way.
 func LoadConfig() (*config.Config, error) {
 	v := viper.New()
 
@@ -21,6 +32,7 @@ func LoadConfig() (*config.Config, error) {
 	v.AddConfigPath("config")
 	v.SetConfigName("app")
 	v.SetConfigType("yaml")
+	v.SetDefault("app.port", 3000)
 
 	// Read base config
 	if err := v.ReadInConfig(); err != nil {
@@ -34,9 +46,8 @@ func LoadConfig() (*config.Config, error) {
 		fmt.Printf("[Gails] Warning: No environment-specific config for %s, using defaults\n", env)
 	}
 
-	// Read environment variables
-	v.AutomaticEnv()
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	// Environment variables override both files
+	bindEnv(v, reflect.TypeOf(config.Config{}), "")
 
 	var cfg config.Config
 	if err := v.Unmarshal(&cfg); err != nil {
@@ -45,3 +56,27 @@ func LoadConfig() (*config.Config, error) {
 
 	return &cfg, nil
 }
+
+// bindEnv binds every leaf key of the config struct t to its environment
+// variables. Viper's AutomaticEnv only sees keys it already knows about, so
+// keys missing from the YAML files would otherwise never pick up the env.
+func bindEnv(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+
+		switch {
+		case f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Time{}):
+			bindEnv(v, f.Type, key+".")
+		case f.Type.Kind() == reflect.Map || f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct:
+			// No single variable can express these.
+		default:
+			envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+			v.BindEnv(key, EnvPrefix+envKey, envKey)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigEnvOverridesYAML(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "database:\n  host: localhost\n  password: from-yaml\n"
+	if err := os.WriteFile(filepath.Join(dir, "config", "app.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	t.Setenv("APP_ENV", "test")
+
+	for _, env := range []string{"GAILS_DATABASE_PASSWORD", "DATABASE_PASSWORD"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "from-env")
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Database.Password != "from-env" {
+				t.Errorf("Database.Password = %q, want %q", cfg.Database.Password, "from-env")
+			}
+			if cfg.Database.Host != "localhost" {
+				t.Errorf("Database.Host = %q, want the YAML value %q", cfg.Database.Host, "localhost")
+			}
+			if cfg.App.Port != 3000 {
+				t.Errorf("App.Port = %d, want the default 3000", cfg.App.Port)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package console is an interactive prompt for inspecting a booted app's
+// data, started by `gails console` or from the app itself:
+//
+//	gails> User.count
+//	gails> User.find 1
+//	gails> Post.all 10
+//
+// Models registered with orm.RegisterModel are queried through their GORM
+// schema (custom table names, soft deletes); other names fall back to the
+// conventional table, so `User` reads from users.
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shaurya/gails/framework"
+	"github.com/shaurya/gails/orm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultLimit is how many rows Model.all prints without a limit argument.
+const defaultLimit = 20
+
+// maxCellWidth truncates long values so tables stay readable.
+const maxCellWidth = 40
+
+// Run reads commands from stdin until exit or EOF. The app should be booted,
+// so app.DB is connected.
+func Run(app *framework.App) {
+	RunWith(app, os.Stdin, os.Stdout)
+}
+
+// RunWith is Run with explicit input and output.
+func RunWith(app *framework.App, in io.Reader, out io.Writer) {
+	c := &console{app: app, out: out}
+
+	fmt.Fprintln(out, "┌───────────────────────────────────────┐")
+	fmt.Fprintln(out, "│  🔧 Gails Console v1.0.0              │")
+	fmt.Fprintln(out, "│  Type 'help' for available commands    │")
+	fmt.Fprintln(out, "│  Type 'exit' or 'quit' to exit         │")
+	fmt.Fprintln(out, "└───────────────────────────────────────┘")
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "gails> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "exit" || line == "quit" || line == "q" {
+			fmt.Fprintln(out, "Goodbye!")
+			return
+		}
+		if err := c.exec(line); err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+}
+
+type console struct {
+	app *framework.App
+	out io.Writer
+}
+
+// exec runs one command line.
+func (c *console) exec(line string) error {
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case "help":
+		c.help()
+		return nil
+	case "env":
+		fmt.Fprintf(c.out, "Environment: %s\n", c.app.Config.App.Env)
+		return nil
+	case "config":
+		c.config()
+		return nil
+	case "routes":
+		fmt.Fprintln(c.out, c.app.Router.Inspect())
+		return nil
+	case "models":
+		return c.models()
+	}
+
+	model, method, ok := strings.Cut(command, ".")
+	if !ok || model == "" {
+		return fmt.Errorf("unknown command %q, type 'help' for available commands", line)
+	}
+	return c.modelCommand(model, method, arg)
+}
+
+func (c *console) help() {
+	fmt.Fprintln(c.out, "Available commands:")
+	fmt.Fprintln(c.out, "  Model.all [n]   — list the first n records (default 20)")
+	fmt.Fprintln(c.out, "  Model.find ID   — show the record with primary key ID")
+	fmt.Fprintln(c.out, "  Model.first     — show the first record")
+	fmt.Fprintln(c.out, "  Model.last      — show the last record")
+	fmt.Fprintln(c.out, "  Model.count     — count records")
+	fmt.Fprintln(c.out, "  models  — list registered models (or tables)")
+	fmt.Fprintln(c.out, "  routes  — list registered routes")
+	fmt.Fprintln(c.out, "  config  — show loaded config")
+	fmt.Fprintln(c.out, "  env     — show current environment")
+	fmt.Fprintln(c.out, "  exit    — exit the console")
+}
+
+// config prints the settings that matter when debugging, without secrets.
+func (c *console) config() {
+	cfg := c.app.Config
+	db := "not connected"
+	if c.app.DB != nil {
+		db = fmt.Sprintf("%s@%s:%d/%s", cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
+	}
+	fmt.Fprintf(c.out, "App:      %s (%s)\n", cfg.App.Name, cfg.App.Env)
+	fmt.Fprintf(c.out, "Port:     %d\n", cfg.App.Port)
+	fmt.Fprintf(c.out, "Database: %s\n", db)
+	fmt.Fprintf(c.out, "Redis:    %t\n", c.app.Redis != nil)
+}
+
+// models lists the registered models, or the database's tables if none are.
+func (c *console) models() error {
+	if names := orm.RegisteredModels(); len(names) > 0 {
+		fmt.Fprintln(c.out, strings.Join(names, "\n"))
+		return nil
+	}
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "No models registered (orm.RegisterModel); tables:")
+	fmt.Fprintln(c.out, strings.Join(tables, "\n"))
+	return nil
+}
+
+// modelCommand runs Model.method.
+func (c *console) modelCommand(model, method, arg string) error {
+	q, pk, err := c.query(model)
+	if err != nil {
+		return err
+	}
+	order := clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: pk}}
+
+	switch method {
+	case "count":
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, n)
+		return nil
+	case "all":
+		limit := defaultLimit
+		if arg != "" {
+			if limit, err = strconv.Atoi(arg); err != nil || limit <= 0 {
+				return fmt.Errorf("%s.all takes a positive limit, got %q", model, arg)
+			}
+		}
+		return c.print(q.Order(order).Limit(limit))
+	case "first":
+		return c.print(q.Order(order).Limit(1))
+	case "last":
+		order.Desc = true
+		return c.print(q.Order(order).Limit(1))
+	case "find":
+		if arg == "" {
+			return fmt.Errorf("usage: %s.find ID", model)
+		}
+		pkColumn := clause.Column{Table: clause.CurrentTable, Name: pk}
+		return c.print(q.Where(clause.Eq{Column: pkColumn, Value: arg}).Limit(1))
+	}
+	return fmt.Errorf("unknown method %q, expected all, find, first, last or count", method)
+}
+
+// query starts a query on model and returns its primary key column.
+func (c *console) query(model string) (*gorm.DB, string, error) {
+	db, err := c.db()
+	if err != nil {
+		return nil, "", err
+	}
+	if m, ok := orm.LookupModel(model); ok {
+		q := db.Model(m)
+		if err := q.Statement.Parse(m); err != nil {
+			return nil, "", err
+		}
+		pk := "id"
+		if f := q.Statement.Schema.PrioritizedPrimaryField; f != nil {
+			pk = f.DBName
+		}
+		return q, pk, nil
+	}
+	return db.Table(db.NamingStrategy.TableName(model)), "id", nil
+}
+
+func (c *console) db() (*gorm.DB, error) {
+	if c.app.DB == nil {
+		return nil, errors.New("no database connection")
+	}
+	return c.app.DB, nil
+}
+
+// print runs q and prints the rows as a table.
+func (c *console) print(q *gorm.DB) error {
+	rows, err := q.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	var records [][]string
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		for i, v := range values {
+			record[i] = formatValue(v)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(c.out, "(no records)")
+		return nil
+	}
+	printTable(c.out, columns, records)
+	return nil
+}
+
+// printTable writes rows under a header, with columns padded to fit.
+func printTable(w io.Writer, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	line := func(cells []string) {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString(" | ")
+			}
+			b.WriteString(cell)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+
+	line(header)
+	sep := make([]string, len(widths))
+	for i, n := range widths {
+		sep[i] = strings.Repeat("-", n)
+	}
+	fmt.Fprintln(w, strings.Join(sep, "-+-"))
+	for _, row := range rows {
+		line(row)
+	}
+	if len(rows) == 1 {
+		fmt.Fprintln(w, "(1 record)")
+	} else {
+		fmt.Fprintf(w, "(%d records)\n", len(rows))
+	}
+}
+
+// formatValue renders a scanned column value for display.
+func formatValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		s = string(v)
+	case time.Time:
+		s = v.Format("2006-01-02 15:04:05")
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.ReplaceAll(s, "\n", " ")
+	if utf8.RuneCountInString(s) > maxCellWidth {
+		s = string([]rune(s)[:maxCellWidth-1]) + "…"
+	}
+	return s
+}
@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,18 +12,27 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/sessions"
+	"github.com/shaurya/gails/cache"
+	"github.com/shaurya/gails/config"
+	"github.com/shaurya/gails/framework/i18n"
+	"github.com/shaurya/gails/framework/validation"
+	"github.com/shaurya/gails/orm"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // H is a shorthand for map[string]any, used for template data and JSON.
 type H map[string]any
 
-// HTTPError represents a typed error with an HTTP status code.
+// HTTPError represents a typed error with an HTTP status code. ErrorCode is a
+// machine-readable identifier for API clients; when empty it is derived from
+// Code (e.g. "not_found", "validation_failed").
 type HTTPError struct {
-	Code    int
-	Message string
-	Errors  map[string][]string
+	Code      int
+	ErrorCode string
+	Message   string
+	Errors    map[string][]string
 }
 
 func (e *HTTPError) Error() string {
@@ -38,6 +48,8 @@ type Context struct {
 	statusCode  int
 	written     bool
 	cacheTTL    time.Duration
+	cleanups    []func()
+	logger      *zap.Logger
 }
 
 // NewContext creates a new Context for a request.
@@ -61,9 +73,41 @@ func (c *Context) Query(key string) string {
 	return c.Request.URL.Query().Get(key)
 }
 
-// --- Request Binding ---
+// --- Headers, Cookies & Body ---
+
+// Header returns a request header value.
+func (c *Context) Header(key string) string {
+	return c.Request.Header.Get(key)
+}
 
-var validate = validator.New()
+// SetHeader sets a response header. Call it before writing the body.
+func (c *Context) SetHeader(key, value string) {
+	c.Response.Header().Set(key, value)
+}
+
+// Cookie returns the named request cookie, or http.ErrNoCookie.
+func (c *Context) Cookie(name string) (*http.Cookie, error) {
+	return c.Request.Cookie(name)
+}
+
+// SetCookie adds a Set-Cookie header to the response.
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.Response, cookie)
+}
+
+// Body reads the raw request body and replaces it with a fresh reader, so it
+// can still be bound afterwards.
+func (c *Context) Body() ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// --- Request Binding ---
 
 // Bind decodes the request body (JSON or form) into v and runs validation.
 // Returns an UnprocessableEntity error if validation fails.
@@ -75,23 +119,47 @@ func (c *Context) Bind(v any) error {
 		err = c.BindForm(v)
 	}
 	if err != nil {
+		if httpErr, ok := err.(*HTTPError); ok {
+			return httpErr
+		}
 		return &HTTPError{Code: http.StatusBadRequest, Message: err.Error()}
 	}
 
-	// Run struct validation
-	if valErr := validate.Struct(v); valErr != nil {
-		errs := make(map[string][]string)
-		for _, e := range valErr.(validator.ValidationErrors) {
-			field := e.Field()
-			msg := fmt.Sprintf("%s is invalid (%s)", field, e.Tag())
-			if e.Param() != "" {
-				msg = fmt.Sprintf("%s must be %s %s", field, e.Tag(), e.Param())
-			}
-			errs[field] = append(errs[field], msg)
-		}
+	return c.validate(v)
+}
+
+// BindQuery maps query string parameters into v and runs validation. Fields
+// are matched by their `query` tag, falling back to the field name:
+//
+//	type ListParams struct {
+//		Page   int    `query:"page" validate:"omitempty,min=1"`
+//		Sort   string `query:"sort"`
+//		Active *bool  `query:"active"`
+//	}
+//
+// Values that can't be converted to the field's type, and validation
+// failures, return an UnprocessableEntity error with field errors.
+func (c *Context) BindQuery(v any) error {
+	errs, err := bindValues(c.Request.URL.Query(), v)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
 		return c.UnprocessableEntity(errs)
 	}
+	return c.validate(v)
+}
 
+// validate runs struct validation on v, returning field errors (in the
+// request's locale) as a 422.
+func (c *Context) validate(v any) error {
+	errs, err := validation.StructFor(c.Locale(), v)
+	if err != nil {
+		return err
+	}
+	if errs != nil {
+		return c.UnprocessableEntity(errs)
+	}
 	return nil
 }
 
@@ -102,12 +170,16 @@ func (c *Context) BindJSON(v any) error {
 		return err
 	}
 	defer c.Request.Body.Close()
-	return json.Unmarshal(body, v)
+	return decodeJSON(body, v)
 }
 
 // BindForm decodes form/multipart data into v using reflection.
 func (c *Context) BindForm(v any) error {
-	if err := c.Request.ParseForm(); err != nil {
+	if c.IsMultipart() {
+		if _, err := c.MultipartForm(); err != nil {
+			return err
+		}
+	} else if err := c.Request.ParseForm(); err != nil {
 		return err
 	}
 	// Simple JSON roundtrip: form values → map → JSON → struct
@@ -139,17 +211,40 @@ func (c *Context) JSON(status int, v any) error {
 
 // Render renders a named template with the given data.
 func (c *Context) Render(template string, data any) error {
+	if h, ok := data.(H); ok && h != nil {
+		if _, set := h["CSPNonce"]; !set {
+			if nonce := c.CSPNonce(); nonce != "" {
+				h["CSPNonce"] = nonce
+			}
+		}
+		if _, set := h["CSRFToken"]; !set {
+			if token := c.CSRFToken(); token != "" {
+				h["CSRFToken"] = token
+			}
+		}
+		if _, set := h["Locale"]; !set {
+			h["Locale"] = c.Locale()
+		}
+		if _, set := h["Flash"]; !set {
+			h["Flash"] = c.Flashes()
+		}
+	}
 	if c.app != nil && c.app.Renderer != nil {
 		c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
 		c.statusCode = http.StatusOK
 		c.written = true
-		return c.app.Renderer.Render(c.Response, template, data)
+		return c.app.Renderer.RenderLocale(c.Response, template, data, c.Locale())
 	}
 	return fmt.Errorf("renderer not initialized")
 }
 
-// Redirect sends an HTTP redirect.
+// Redirect sends an HTTP redirect. Sessions loaded during the request (and
+// any flash set on them) are saved first, since nothing can be written after.
 func (c *Context) Redirect(url string) error {
+	if sessionStore != nil {
+		dropSetCookie(c.Response.Header(), SessionName)
+		sessions.Save(c.Request, c.Response)
+	}
 	http.Redirect(c.Response, c.Request, url, http.StatusFound)
 	c.written = true
 	return nil
@@ -166,14 +261,49 @@ func (c *Context) RedirectBack(fallback string) error {
 
 // --- Sessions & Flash ---
 
+// SessionName is the name of the app session cookie.
+const SessionName = "gails_session"
+
+const defaultSessionSecret = "gails-default-secret-change-me"
+
 var sessionStore sessions.Store
 
-// InitSessionStore initializes the session store.
-func InitSessionStore(secret string) {
+// InitSessionStore builds the session store from cfg.Sessions. With store
+// "redis", session data is kept in Redis under key_prefix and only the session
+// ID goes in the cookie; anything else uses signed cookies. TTL is in seconds.
+func InitSessionStore(cfg *config.Config) error {
+	secret := cfg.App.SecretKeyBase
 	if secret == "" {
-		secret = "gails-default-secret-change-me"
+		secret = defaultSessionSecret
+	}
+	if cfg.Sessions.Store == "redis" {
+		store, err := cache.NewRedisSessionStoreFromConfig(cfg.Redis, cfg.Sessions, secret)
+		if err != nil {
+			return err
+		}
+		sessionStore = store
+		return nil
+	}
+	store := sessions.NewCookieStore([]byte(secret))
+	if cfg.Sessions.TTL > 0 {
+		store.MaxAge(cfg.Sessions.TTL)
+	}
+	sessionStore = store
+	return nil
+}
+
+// SessionStore returns the app's session store. If InitSessionStore hasn't
+// run, a cookie store with the default secret is created.
+func SessionStore() sessions.Store {
+	if sessionStore == nil {
+		sessionStore = sessions.NewCookieStore([]byte(defaultSessionSecret))
 	}
-	sessionStore = sessions.NewCookieStore([]byte(secret))
+	return sessionStore
+}
+
+// SetSessionStore replaces the app's session store.
+func SetSessionStore(store sessions.Store) {
+	sessionStore = store
 }
 
 // Session returns the current session.
@@ -181,18 +311,24 @@ func (c *Context) Session() *sessions.Session {
 	if sessionStore == nil {
 		return nil
 	}
-	sess, _ := sessionStore.Get(c.Request, "gails_session")
+	sess, _ := sessionStore.Get(c.Request, SessionName)
 	return sess
 }
 
-// Flash sets a flash message.
+// flashPrefix namespaces flash keys in the session, so Flashes can find them.
+const flashPrefix = "_flash_"
+
+// Flash sets a flash message for the next request, e.g. after a redirect:
+//
+//	ctx.Flash("notice", "Post created")
+//	return ctx.Redirect("/posts")
 func (c *Context) Flash(key, msg string) {
 	sess := c.Session()
 	if sess == nil {
 		return
 	}
-	sess.AddFlash(msg, key)
-	sess.Save(c.Request, c.Response)
+	sess.AddFlash(msg, flashPrefix+key)
+	c.saveSession(sess)
 }
 
 // GetFlash retrieves and clears a flash message.
@@ -201,19 +337,91 @@ func (c *Context) GetFlash(key string) string {
 	if sess == nil {
 		return ""
 	}
-	flashes := sess.Flashes(key)
-	sess.Save(c.Request, c.Response)
-	if len(flashes) > 0 {
-		return fmt.Sprint(flashes[0])
+	flashes := sess.Flashes(flashPrefix + key)
+	if len(flashes) == 0 {
+		return ""
+	}
+	c.saveSession(sess)
+	return fmt.Sprint(flashes[0])
+}
+
+// Flashes retrieves and clears all flash messages, keyed by type ("notice",
+// "alert", ...). Render passes them to views as .Flash.
+func (c *Context) Flashes() map[string][]string {
+	sess := c.Session()
+	if sess == nil {
+		return nil
+	}
+	var out map[string][]string
+	for k := range sess.Values {
+		name, ok := k.(string)
+		if !ok || !strings.HasPrefix(name, flashPrefix) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string][]string)
+		}
+		key := strings.TrimPrefix(name, flashPrefix)
+		for _, f := range sess.Flashes(name) {
+			out[key] = append(out[key], fmt.Sprint(f))
+		}
+	}
+	if out != nil {
+		c.saveSession(sess)
 	}
-	return ""
+	return out
+}
+
+// saveSession saves sess, replacing any copy of its cookie already set on
+// this response so the client only gets the latest.
+func (c *Context) saveSession(sess *sessions.Session) error {
+	dropSetCookie(c.Response.Header(), sess.Name())
+	return sess.Save(c.Request, c.Response)
+}
+
+func dropSetCookie(h http.Header, name string) {
+	cookies := h.Values("Set-Cookie")
+	kept := cookies[:0:0]
+	for _, line := range cookies {
+		if !strings.HasPrefix(line, name+"=") {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(cookies) {
+		return
+	}
+	h.Del("Set-Cookie")
+	for _, line := range kept {
+		h.Add("Set-Cookie", line)
+	}
+}
+
+// --- Translations ---
+
+// Locale returns the request's locale as set by the Locale middleware,
+// falling back to the app default.
+func (c *Context) Locale() string {
+	return i18n.LocaleFrom(c.Request.Context())
+}
+
+// T translates key in the request's locale.
+func (c *Context) T(key string, vars i18n.Vars) string {
+	return i18n.TFor(c.Locale(), key, vars)
+}
+
+// TN translates key with a plural form for count in the request's locale.
+func (c *Context) TN(key string, count int, vars i18n.Vars) string {
+	return i18n.TNFor(c.Locale(), key, count, vars)
 }
 
 // --- Current User ---
 
 type contextKey string
 
-const userContextKey contextKey = "gails_current_user"
+const (
+	userContextKey   contextKey = "gails_current_user"
+	userIDContextKey contextKey = "gails_user_id"
+)
 
 // CurrentUser returns the current authenticated user (if set).
 func (c *Context) CurrentUser() any {
@@ -230,6 +438,18 @@ func (c *Context) SetCurrentUser(u any) {
 	c.Request = c.Request.WithContext(ctx)
 }
 
+// WithUserID returns ctx carrying the authenticated user's ID. Auth
+// middleware (JWT, API keys) sets it; UserIDFromContext reads it back.
+func WithUserID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, userIDContextKey, id)
+}
+
+// UserIDFromContext returns the user ID set by WithUserID.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(userIDContextKey).(uint)
+	return id, ok
+}
+
 // --- Request Info ---
 
 // RequestID returns the request ID from the X-Request-ID header or chi middleware.
@@ -240,6 +460,54 @@ func (c *Context) RequestID() string {
 	return c.Request.Header.Get("X-Request-ID")
 }
 
+// Context returns the request's context. It is cancelled when the client
+// goes away or a Timeout deadline passes; pass it to slow calls.
+func (c *Context) Context() context.Context {
+	return c.Request.Context()
+}
+
+// Deadline returns the request's deadline, if it has one (see Timeout).
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.Request.Context().Deadline()
+}
+
+// DB returns the app database bound to the request context, so queries are
+// cancelled with the request and participate in per-request instrumentation.
+// The context also names the logged-in user for orm.RegisterAuditLog.
+func (c *Context) DB() *gorm.DB {
+	if c.app == nil || c.app.DB == nil {
+		return nil
+	}
+	ctx := c.Request.Context()
+	if id, ok := c.currentUserID(); ok {
+		ctx = orm.WithAuditUser(ctx, id)
+	}
+	return c.app.DB.WithContext(ctx)
+}
+
+// currentUserID identifies the logged-in user for the request log and orm's
+// audit log: the session's user_id, as for auth.UserID, or the ID set by auth
+// middleware.
+func (c *Context) currentUserID() (uint, bool) {
+	// Only look in sessions the client already has; Session would otherwise
+	// start a new one.
+	if _, err := c.Request.Cookie(SessionName); err == nil {
+		if sess := c.Session(); sess != nil {
+			switch id := sess.Values["user_id"].(type) {
+			case uint:
+				return id, true
+			case int:
+				return uint(id), true
+			case int64:
+				return uint(id), true
+			case uint64:
+				return uint(id), true
+			}
+		}
+	}
+	return UserIDFromContext(c.Request.Context())
+}
+
 // IsJSON returns true if the request Content-Type is application/json.
 func (c *Context) IsJSON() bool {
 	ct := c.Request.Header.Get("Content-Type")
@@ -268,9 +536,14 @@ func (c *Context) Forbidden(msg string) error {
 	return &HTTPError{Code: http.StatusForbidden, Message: msg}
 }
 
+// Conflict returns a 409 error, e.g. for an optimistic locking conflict.
+func (c *Context) Conflict(msg string) error {
+	return &HTTPError{Code: http.StatusConflict, Message: msg}
+}
+
 // UnprocessableEntity returns a 422 error with field-level validation errors.
 func (c *Context) UnprocessableEntity(errors map[string][]string) error {
-	return &HTTPError{Code: http.StatusUnprocessableEntity, Message: "Validation failed", Errors: errors}
+	return &HTTPError{Code: http.StatusUnprocessableEntity, ErrorCode: "validation_failed", Message: "Validation failed", Errors: errors}
 }
 
 // InternalError returns a 500 error.
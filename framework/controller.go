@@ -1,7 +1,10 @@
 package framework
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
 )
@@ -29,6 +32,8 @@ type ResourceController interface {
 func ActionHandler(action Action, app *App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := NewContext(w, r, app)
+		ctx.logger = requestLogger(r)
+		defer ctx.cleanup()
 
 		// Panic recovery per-request
 		defer func() {
@@ -51,16 +56,27 @@ func ActionHandler(action Action, app *App) http.HandlerFunc {
 // handleActionError maps errors to the correct HTTP status codes.
 func handleActionError(ctx *Context, err error) {
 	if ctx.written {
-		return // Response already sent
+		// Response already sent (e.g. a stream failed midway); just record it.
+		Log.Warn("Controller error after response was sent", zap.Error(err))
+		return
+	}
+
+	// Giving up at a Timeout deadline gets the timeout response.
+	if errors.Is(err, context.DeadlineExceeded) {
+		if timeoutErr, ok := ctx.Request.Context().Value(timeoutErrorKey{}).(*HTTPError); ok {
+			err = timeoutErr
+		}
+	}
+
+	// Model validation failures (e.g. *orm.ValidationError) become a 422.
+	var fieldErr interface{ FieldErrors() map[string][]string }
+	if errors.As(err, &fieldErr) {
+		err = ctx.UnprocessableEntity(fieldErr.FieldErrors())
 	}
 
 	if httpErr, ok := err.(*HTTPError); ok {
-		if ctx.IsJSON() || httpErr.Errors != nil {
-			response := H{"error": httpErr.Message}
-			if httpErr.Errors != nil {
-				response = H{"errors": httpErr.Errors}
-			}
-			ctx.JSON(httpErr.Code, response)
+		if wantsJSONError(ctx) || httpErr.Errors != nil {
+			ctx.JSON(httpErr.Code, errorEnvelope(ctx, httpErr.ErrorCode, httpErr.Code, httpErr.Message, httpErr.Errors))
 		} else {
 			http.Error(ctx.Response, httpErr.Message, httpErr.Code)
 		}
@@ -68,16 +84,56 @@ func handleActionError(ctx *Context, err error) {
 	}
 
 	// Default: 500 Internal Server Error
-	if Log != nil {
-		Log.Error("Unhandled controller error", zap.Error(err))
-	}
-	if ctx.IsJSON() {
-		ctx.JSON(http.StatusInternalServerError, H{"error": "Internal Server Error"})
+	Log.Error("Unhandled controller error", zap.Error(err))
+	if wantsJSONError(ctx) {
+		ctx.JSON(http.StatusInternalServerError, errorEnvelope(ctx, "internal_error", http.StatusInternalServerError, "Internal Server Error", nil))
 	} else {
 		http.Error(ctx.Response, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
+// wantsJSONError reports whether an error should be sent as the JSON
+// envelope. That follows the Accept header (or ?format=); only a client with
+// no preference falls back to the request's Content-Type.
+func wantsJSONError(ctx *Context) bool {
+	if f := ctx.Format(); f != "" {
+		return f == "json"
+	}
+	return ctx.IsJSON()
+}
+
+// errorEnvelope builds the JSON body for error responses:
+//
+//	{"error": {"code": "validation_failed", "message": "...", "fields": {...}, "request_id": "..."}}
+func errorEnvelope(ctx *Context, code string, status int, message string, fields map[string][]string) H {
+	if code == "" {
+		code = errorCode(status)
+	}
+	body := H{"code": code, "message": message}
+	if fields != nil {
+		body["fields"] = fields
+	}
+	if id := ctx.RequestID(); id != "" {
+		body["request_id"] = id
+	}
+	return H{"error": body}
+}
+
+// errorCode derives a snake_case code from an HTTP status, e.g. 404 -> "not_found".
+func errorCode(status int) string {
+	switch status {
+	case http.StatusUnprocessableEntity:
+		return "validation_failed"
+	case http.StatusInternalServerError:
+		return "internal_error"
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.NewReplacer("-", " ", "'", "").Replace(text)), " ", "_")
+}
+
 // Wrap is a convenience alias for ActionHandler without an app reference.
 func Wrap(action Action) http.HandlerFunc {
 	return ActionHandler(action, nil)
@@ -0,0 +1,130 @@
+package framework
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCookie is returned by SignedCookie and EncryptedCookie when the
+// cookie was tampered with, or set with a different secret.
+var ErrInvalidCookie = errors.New("framework: invalid cookie signature")
+
+// SetSignedCookie sets cookie name to value, signed with the app's
+// secret_key_base so SignedCookie can tell if it was changed. The value
+// itself is readable by the client; use SetEncryptedCookie to hide it.
+//
+// opts supplies the other cookie attributes (Path, MaxAge, Secure, ...); its
+// Name and Value are ignored. With nil opts the cookie is HttpOnly,
+// SameSite=Lax, on path "/", and Secure in production.
+func (c *Context) SetSignedCookie(name, value string, opts *http.Cookie) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	c.setCookie(name, payload+"."+c.cookieMAC(name, payload), opts)
+}
+
+// SignedCookie returns the value of a cookie set with SetSignedCookie. It
+// returns http.ErrNoCookie if there is none and ErrInvalidCookie if the
+// signature doesn't match.
+func (c *Context) SignedCookie(name string) (string, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	payload, mac, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(c.cookieMAC(name, payload))) {
+		return "", ErrInvalidCookie
+	}
+	value, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(value), nil
+}
+
+// SetEncryptedCookie is SetSignedCookie with the value encrypted (AES-GCM,
+// keyed from secret_key_base), so the client can neither read nor change it.
+func (c *Context) SetEncryptedCookie(name, value string, opts *http.Cookie) error {
+	gcm, err := c.cookieCipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	// The name is authenticated too, so a value can't be replayed under
+	// another cookie's name.
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	c.setCookie(name, base64.RawURLEncoding.EncodeToString(sealed), opts)
+	return nil
+}
+
+// EncryptedCookie returns the value of a cookie set with
+// SetEncryptedCookie. It returns http.ErrNoCookie if there is none and
+// ErrInvalidCookie if it doesn't decrypt.
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := c.cookieCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", ErrInvalidCookie
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(value), nil
+}
+
+// setCookie sets name=value with the attributes from opts, or the defaults.
+func (c *Context) setCookie(name, value string, opts *http.Cookie) {
+	cookie := http.Cookie{
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.app != nil && c.app.Config.App.Env == "production",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if opts != nil {
+		cookie = *opts
+	}
+	cookie.Name, cookie.Value = name, value
+	http.SetCookie(c.Response, &cookie)
+}
+
+// cookieMAC signs payload for cookie name.
+func (c *Context) cookieMAC(name, payload string) string {
+	mac := hmac.New(sha256.New, c.cookieKey("gails signed cookie"))
+	mac.Write([]byte(name + "=" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Context) cookieCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.cookieKey("gails encrypted cookie"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// cookieKey derives a 32-byte key for purpose from secret_key_base, so
+// signing and encryption never share a key with each other or the sessions.
+func (c *Context) cookieKey(purpose string) []byte {
+	secret := defaultSessionSecret
+	if c.app != nil && c.app.Config.App.SecretKeyBase != "" {
+		secret = c.app.Config.App.SecretKeyBase
+	}
+	key, _ := hkdf.Key(sha256.New, []byte(secret), nil, purpose, 32)
+	return key
+}
@@ -0,0 +1,84 @@
+package framework
+
+import (
+	"errors"
+	"os"
+
+	"github.com/pressly/goose/v3"
+	"github.com/shaurya/gails/cache"
+	"github.com/shaurya/gails/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrationsDir is where Boot looks for migrations when app.auto_migrate is set.
+const MigrationsDir = "db/migrations"
+
+// connectServices connects the database and Redis from config, unless they
+// were set before Boot, and picks a cache backed by Redis when it's up.
+// Database connects are retried per connect_retry, except in development. In
+// production a failed connection stops the app; elsewhere it is logged and
+// the app boots without it.
+func (a *App) connectServices() {
+	production := a.Config.App.Env == "production"
+	unavailable := func(msg string, err error) {
+		if production {
+			Log.Fatal(msg, zap.Error(err))
+		}
+		Log.Warn(msg+", continuing without it", zap.Error(err))
+	}
+
+	if a.DB == nil && a.Config.Database.Host != "" {
+		dbConfig := a.Config.Database
+		if a.Config.App.Env == "development" {
+			// Locally the app boots fine without a database, so don't make
+			// every restart wait out the retries.
+			dbConfig.ConnectRetry.MaxAttempts = 1
+		}
+		conn, err := db.Connect(dbConfig)
+		if err != nil {
+			unavailable("Failed to connect to PostgreSQL", err)
+		} else {
+			a.DB = conn
+		}
+	}
+	if a.DB != nil && a.Config.App.AutoMigrate {
+		if err := autoMigrate(a.DB, MigrationsDir); err != nil {
+			Log.Error("Failed to run migrations", zap.Error(err))
+		}
+	}
+
+	if a.Redis == nil && a.Config.Redis.URL != "" {
+		adapter, err := cache.NewRedisAdapter(a.Config.Redis)
+		if err != nil {
+			unavailable("Failed to connect to Redis", err)
+		} else {
+			a.Redis = adapter.Client
+			if a.Cache == nil {
+				a.Cache = adapter
+			}
+		}
+	}
+	if a.Cache == nil {
+		if a.Redis != nil {
+			a.Cache = &cache.RedisAdapter{Client: a.Redis}
+		} else {
+			a.Cache = cache.NewMemoryAdapter()
+		}
+	}
+}
+
+// autoMigrate runs the pending migrations in dir, if it exists.
+func autoMigrate(db *gorm.DB, dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.Up(sqlDB, dir)
+}
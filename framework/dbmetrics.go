@@ -0,0 +1,90 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	queryStartKey = "gails:query_start"
+	querySpanKey  = "gails:query_span"
+)
+
+// InstrumentDB installs GORM callbacks that time every query, record it in
+// gails_db_query_duration_seconds, trace it as a child span of the query's
+// context (see OTel), and leave a breadcrumb for queries slower than
+// slowQueryMs (default 200). Breadcrumbs are filed under the request ID of
+// the query's context, so bind queries to the request (see Context.DB).
+func InstrumentDB(db *gorm.DB, slowQueryMs int) {
+	threshold := time.Duration(slowQueryMs) * time.Millisecond
+	if threshold <= 0 {
+		threshold = 200 * time.Millisecond
+	}
+
+	start := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			tx.InstanceSet(queryStartKey, time.Now())
+			if ctx := tx.Statement.Context; ctx != nil && trace.SpanFromContext(ctx).SpanContext().IsValid() {
+				_, span := Tracer().Start(ctx, "db."+operation,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(
+						attribute.String("db.system", "postgresql"),
+						attribute.String("db.operation.name", operation),
+					),
+				)
+				tx.InstanceSet(querySpanKey, span)
+			}
+		}
+	}
+	finish := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(v.(time.Time))
+		RecordDBQuery(elapsed)
+
+		if v, ok := tx.InstanceGet(querySpanKey); ok {
+			span := v.(trace.Span)
+			span.SetAttributes(
+				attribute.String("db.query.text", tx.Statement.SQL.String()),
+				attribute.String("db.collection.name", tx.Statement.Table),
+				attribute.Int64("db.rows_affected", tx.RowsAffected),
+			)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+			span.End()
+		}
+
+		if elapsed < threshold || tx.Statement.Context == nil {
+			return
+		}
+		if reqID := middleware.GetReqID(tx.Statement.Context); reqID != "" {
+			GlobalBreadcrumbs.Add(reqID, "warn", fmt.Sprintf("Slow query (%s): %s",
+				elapsed.Round(time.Millisecond), tx.Statement.SQL.String()))
+		}
+	}
+
+	cb := db.Callback()
+	cb.Create().Before("gorm:create").Register("gails:metrics_start", start("create"))
+	cb.Create().After("gorm:create").Register("gails:metrics_finish", finish)
+	cb.Query().Before("gorm:query").Register("gails:metrics_start", start("query"))
+	cb.Query().After("gorm:query").Register("gails:metrics_finish", finish)
+	cb.Update().Before("gorm:update").Register("gails:metrics_start", start("update"))
+	cb.Update().After("gorm:update").Register("gails:metrics_finish", finish)
+	cb.Delete().Before("gorm:delete").Register("gails:metrics_start", start("delete"))
+	cb.Delete().After("gorm:delete").Register("gails:metrics_finish", finish)
+	cb.Row().Before("gorm:row").Register("gails:metrics_start", start("row"))
+	cb.Row().After("gorm:row").Register("gails:metrics_finish", finish)
+	cb.Raw().Before("gorm:raw").Register("gails:metrics_start", start("raw"))
+	cb.Raw().After("gorm:raw").Register("gails:metrics_finish", finish)
+}
@@ -8,8 +8,10 @@ import (
 	"os"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"strings"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 )
 
@@ -17,6 +19,7 @@ type StackFrame struct {
 	Function string
 	File     string
 	Line     int
+	Column   int
 	Code     []string
 	IsUser   bool
 }
@@ -31,6 +34,8 @@ type ErrorPageData struct {
 	RequestMethod  string
 	RequestURL     string
 	RequestHeaders http.Header
+	QueryCount     int
+	Breadcrumbs    []Breadcrumb
 	Env            string
 	GoVersion      string
 	GailsVersion   string
@@ -40,6 +45,11 @@ func DevErrorHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
 	w.WriteHeader(http.StatusInternalServerError)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
+	stack := debug.Stack()
+	if pe, ok := err.(*PanicError); ok {
+		err, stack = pe.Value, pe.Stack
+	}
+
 	data := ErrorPageData{
 		ErrorType:      fmt.Sprintf("%T", err),
 		Message:        fmt.Sprintf("%v", err),
@@ -51,12 +61,22 @@ func DevErrorHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
 		GailsVersion:   "v1.0.0",
 	}
 
-	stack := debug.Stack()
+	if stats := QueryStatsFromContext(r.Context()); stats != nil {
+		data.QueryCount = stats.Count()
+	}
+
+	// Breadcrumbs are consumed here so they can't leak into later requests.
+	if reqID := middleware.GetReqID(r.Context()); reqID != "" {
+		data.Breadcrumbs = GlobalBreadcrumbs.Get(reqID)
+		GlobalBreadcrumbs.Clear(reqID)
+	}
+
 	data.StackTrace = parseStackTrace(stack)
 
 	if len(data.StackTrace) > 0 {
 		data.File = data.StackTrace[0].File
 		data.Line = data.StackTrace[0].Line
+		data.Column = data.StackTrace[0].Column
 	}
 
 	funcs := template.FuncMap{
@@ -71,35 +91,36 @@ func ProdErrorHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
 	w.WriteHeader(http.StatusInternalServerError)
 	w.Header().Set("Content-Type", "application/json")
 	fmt.Fprintf(w, `{"error": "Something went wrong"}`)
-	Log.Error("Panic recovered", zap.Any("panic", err))
+	if pe, ok := err.(*PanicError); ok {
+		Log.Error("Panic recovered", zap.Any("panic", pe.Value), zap.ByteString("stack", pe.Stack))
+	} else {
+		Log.Error("Panic recovered", zap.Any("panic", err))
+	}
+	GlobalBreadcrumbs.Clear(middleware.GetReqID(r.Context()))
 }
 
 func parseStackTrace(stack []byte) []StackFrame {
 	lines := strings.Split(string(stack), "\n")
 	var frames []StackFrame
-	// debug.Stack() output starts with "goroutine ... [running]:" and then pairs of lines: function and file:line
-	for i := 1; i < len(lines)-1; i += 2 {
-		line1 := strings.TrimSpace(lines[i])
-		line2 := strings.TrimSpace(lines[i+1])
-		if line1 == "" || line2 == "" {
+	// debug.Stack() output is a "goroutine N [running]:" header followed by
+	// pairs of lines: the function, then a tab-indented "file:line +0xabc".
+	for i := 1; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "\t") {
 			continue
 		}
-
-		// Parse file and line from line2 (e.g., "/path/to/file.go:line +0xabc")
-		parts := strings.Split(line2, ":")
-		if len(parts) < 2 {
+		function := strings.TrimSpace(lines[i-1])
+		file, lineNum, col, ok := parseFileLine(strings.TrimSpace(lines[i]))
+		if function == "" || !ok {
 			continue
 		}
-		file := parts[0]
-		lineParts := strings.Split(parts[1], " ")
-		var lineNum int
-		fmt.Sscanf(lineParts[0], "%d", &lineNum)
 
+		slashed := strings.ReplaceAll(file, "\\", "/")
 		frame := StackFrame{
-			Function: line1,
+			Function: function,
 			File:     file,
 			Line:     lineNum,
-			IsUser:   !strings.Contains(file, "runtime/") && !strings.Contains(file, "github.com/shaurya/gails/framework"),
+			Column:   col,
+			IsUser:   !strings.Contains(slashed, "runtime/") && !strings.Contains(slashed, "github.com/shaurya/gails/framework"),
 		}
 
 		if frame.IsUser {
@@ -111,6 +132,37 @@ func parseStackTrace(stack []byte) []StackFrame {
 	return frames
 }
 
+// parseFileLine splits "file:line", "file:line:col" or either followed by
+// " +0xabc". It parses from the right so colons inside the path, such as a
+// Windows drive letter, stay part of the file name.
+func parseFileLine(s string) (file string, line, col int, ok bool) {
+	if i := strings.LastIndex(s, " +0x"); i >= 0 {
+		s = s[:i]
+	}
+	rest, n, ok := cutTrailingNumber(s)
+	if !ok {
+		return "", 0, 0, false
+	}
+	if rest2, m, ok := cutTrailingNumber(rest); ok {
+		return rest2, m, n, true
+	}
+	return rest, n, 0, true
+}
+
+// cutTrailingNumber splits s at its last colon when everything after the
+// colon is digits.
+func cutTrailingNumber(s string) (rest string, n int, ok bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i <= 0 || i == len(s)-1 || s[i+1] < '0' || s[i+1] > '9' {
+		return s, 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0, false
+	}
+	return s[:i], n, true
+}
+
 func getSourceSnippet(file string, line int) []string {
 	f, err := os.Open(file)
 	if err != nil {
@@ -166,6 +218,7 @@ const errorTemplate = `
         table { width: 100%; border-collapse: collapse; font-size: 13px; }
         th, td { text-align: left; padding: 8px 15px; border-bottom: 1px solid #eee; }
         th { background: #fcfcfc; color: #777; width: 30%; }
+        .level-warn, .level-error { color: #b94a48; font-weight: bold; }
         .copy-btn { font-size: 12px; padding: 4px 8px; border: 1px solid #ccc; background: #fff; border-radius: 3px; cursor: pointer; }
     </style>
 </head>
@@ -174,7 +227,7 @@ const errorTemplate = `
         <header>
             <h1>{{.ErrorType}}</h1>
             <div class="message">{{.Message}}</div>
-            <div class="file-info">{{.File}}:{{.Line}}</div>
+            <div class="file-info">{{.File}}:{{.Line}}{{if .Column}}:{{.Column}}{{end}}</div>
         </header>
 
         <div class="section">
@@ -190,7 +243,7 @@ const errorTemplate = `
                 {{range .StackTrace}}
                 <div class="frame {{if not .IsUser}}framework{{end}}">
                     <div class="func">{{.Function}}</div>
-                    <div class="file">{{.File}}:{{.Line}}</div>
+                    <div class="file">{{.File}}:{{.Line}}{{if .Column}}:{{.Column}}{{end}}</div>
                 </div>
                 {{end}}
             </div>
@@ -202,9 +255,23 @@ const errorTemplate = `
                 <table>
                     <tr><th>Method</th><td>{{.RequestMethod}}</td></tr>
                     <tr><th>URL</th><td>{{.RequestURL}}</td></tr>
+                    {{if .QueryCount}}<tr><th>SQL Queries</th><td>{{.QueryCount}}</td></tr>{{end}}
+                </table>
+            </div>
+        </div>
+
+        {{if .Breadcrumbs}}
+        <div class="section">
+            <div class="section-header">Breadcrumbs</div>
+            <div class="section-body">
+                <table>
+                    {{range .Breadcrumbs}}
+                    <tr><th>{{.Timestamp.Format "15:04:05.000"}} <span class="level-{{.Level}}">{{.Level}}</span></th><td>{{.Message}}</td></tr>
+                    {{end}}
                 </table>
             </div>
         </div>
+        {{end}}
         
         <div class="section">
             <div class="section-header">Request Headers</div>
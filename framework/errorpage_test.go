@@ -0,0 +1,29 @@
+package framework
+
+import "testing"
+
+func TestParseStackTrace(t *testing.T) {
+	stack := "goroutine 7 [running]:\n" +
+		"main.handler(...)\n" +
+		"\tC:/Users/dev/app/main.go:42 +0x1d\n" +
+		"main.(*PostsController).Show(0xc000010000)\n" +
+		"\tC:\\Users\\dev\\app\\posts.go:17:9\n" +
+		"github.com/shaurya/gails/framework.Recovery.func1()\n" +
+		"\t/home/dev/go/pkg/mod/github.com/shaurya/gails/framework/errorpage.go:50 +0x65\n"
+
+	frames := parseStackTrace([]byte(stack))
+	want := []StackFrame{
+		{Function: "main.handler(...)", File: "C:/Users/dev/app/main.go", Line: 42, IsUser: true},
+		{Function: "main.(*PostsController).Show(0xc000010000)", File: `C:\Users\dev\app\posts.go`, Line: 17, Column: 9, IsUser: true},
+		{Function: "github.com/shaurya/gails/framework.Recovery.func1()", File: "/home/dev/go/pkg/mod/github.com/shaurya/gails/framework/errorpage.go", Line: 50},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("parsed %d frames, want %d: %+v", len(frames), len(want), frames)
+	}
+	for i, w := range want {
+		f := frames[i]
+		if f.Function != w.Function || f.File != w.File || f.Line != w.Line || f.Column != w.Column || f.IsUser != w.IsUser {
+			t.Errorf("frame %d = %+v, want %+v", i, f, w)
+		}
+	}
+}
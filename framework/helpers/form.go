@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 	"time"
+	"unicode"
 )
 
 type FormBuilder struct {
@@ -13,18 +14,36 @@ type FormBuilder struct {
 	Errors map[string][]string
 }
 
-func FormFor(model any, action, method string, errors map[string][]string) template.HTML {
+// FormFor renders a form for every exported field of model. Pass the
+// request's CSRF token (.CSRFToken in views) to include it as csrf_token.
+//
+// Input types are inferred from each field's Go type; override them with a
+// `form` tag, or skip a field with `form:"-"`:
+//
+//	Password string `form:"type=password"`
+//	Bio      string `form:"as=textarea"`
+func FormFor(model any, action, method string, errors map[string][]string, csrfToken ...string) template.HTML {
 	v := reflect.ValueOf(model)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 
+	// Browsers only submit GET and POST; other verbs are POSTed with a _method
+	// field that the MethodOverride middleware routes on.
+	override := ""
+	switch strings.ToUpper(method) {
+	case "PUT", "PATCH", "DELETE":
+		override = fmt.Sprintf(`<input type="hidden" name="_method" value="%s">`, strings.ToUpper(method))
+		method = "post"
+	}
+	override += string(CSRFField(csrfToken...))
+
 	if v.Kind() != reflect.Struct {
-		return template.HTML(fmt.Sprintf(`<form action="%s" method="%s"></form>`, action, method))
+		return template.HTML(fmt.Sprintf(`<form action="%s" method="%s">%s</form>`, action, method, override))
 	}
 
 	html := fmt.Sprintf(`<form action="%s" method="%s">`, action, method)
-	html += `<input type="hidden" name="csrf_token" value="token">` // Placeholder
+	html += override
 
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
@@ -35,12 +54,26 @@ func FormFor(model any, action, method string, errors map[string][]string) templ
 		if f.Name == "Model" || f.Name == "ID" || f.Name == "CreatedAt" || f.Name == "UpdatedAt" || f.Name == "DeletedAt" {
 			continue // Skip framework fields
 		}
+		opts := formOptions(f)
+		if opts["-"] != "" {
+			continue
+		}
 
 		html += `<div class="form-group mb-3">`
 		html += string(LabelFor(f.Name))
 
-		inputType := InferInputType(v.Field(i).Interface())
-		html += string(InputFor(model, f.Name, inputType, errors))
+		inputType := opts["type"]
+		if inputType == "" {
+			inputType = InferInputType(v.Field(i).Interface())
+		}
+		switch {
+		case opts["as"] == "textarea":
+			html += string(TextareaFor(model, f.Name, errors))
+		case opts["as"] == "checkbox" || inputType == "checkbox":
+			html += string(CheckboxFor(model, f.Name))
+		default:
+			html += string(InputFor(model, f.Name, inputType, errors))
+		}
 		html += `</div>`
 	}
 
@@ -50,6 +83,32 @@ func FormFor(model any, action, method string, errors map[string][]string) templ
 	return template.HTML(html)
 }
 
+// CSRFField renders the hidden csrf_token input, or nothing without a token.
+func CSRFField(token ...string) template.HTML {
+	if len(token) == 0 || token[0] == "" {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="csrf_token" value="%s">`, template.HTMLEscapeString(token[0])))
+}
+
+// formOptions parses a `form:"type=password,as=textarea"` tag. A bare "-"
+// is returned as {"-": "-"}.
+func formOptions(f reflect.StructField) map[string]string {
+	opts := make(map[string]string)
+	for _, part := range strings.Split(f.Tag.Get("form"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			value = key
+		}
+		opts[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return opts
+}
+
 func InputFor(model any, fieldName string, inputType string, errors map[string][]string) template.HTML {
 	val := getFieldValue(model, fieldName)
 	errs := errors[fieldName]
@@ -59,8 +118,8 @@ func InputFor(model any, fieldName string, inputType string, errors map[string][
 		errorClass = " is-invalid"
 	}
 
-	html := fmt.Sprintf(`<input type="%s" name="%s" value="%v" class="form-control%s">`,
-		inputType, fieldName, val, errorClass)
+	html := fmt.Sprintf(`<input type="%s" name="%s" value="%s" class="form-control%s">`,
+		inputType, fieldName, formValue(val, inputType), errorClass)
 
 	if len(errs) > 0 {
 		html += fmt.Sprintf(`<div class="invalid-feedback">%s</div>`, strings.Join(errs, ", "))
@@ -83,8 +142,8 @@ func TextareaFor(model any, fieldName string, errors map[string][]string) templa
 		errorClass = " is-invalid"
 	}
 
-	html := fmt.Sprintf(`<textarea name="%s" class="form-control%s">%v</textarea>`,
-		fieldName, errorClass, val)
+	html := fmt.Sprintf(`<textarea name="%s" class="form-control%s">%s</textarea>`,
+		fieldName, errorClass, formValue(val, "textarea"))
 
 	if len(errs) > 0 {
 		html += fmt.Sprintf(`<div class="invalid-feedback">%s</div>`, strings.Join(errs, ", "))
@@ -139,7 +198,7 @@ func ErrorMessages(errors map[string][]string) template.HTML {
 	html := `<div class="alert alert-danger"><ul>`
 	for field, errs := range errors {
 		for _, err := range errs {
-			html += fmt.Sprintf("<li>%s: %s</li>", humanize(field), err)
+			html += fmt.Sprintf("<li>%s: %s</li>", template.HTMLEscapeString(humanize(field)), template.HTMLEscapeString(err))
 		}
 	}
 	html += `</ul></div>`
@@ -167,18 +226,57 @@ func getFieldValue(model any, fieldName string) any {
 	return field.Interface()
 }
 
+// humanize turns a field name into a label: FirstName -> "First Name",
+// UserID -> "User ID", Address2 -> "Address 2", first_name -> "First name".
 func humanize(s string) string {
-	// Simple humanization: FirstName -> First Name
-	var result []string
-	start := 0
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result = append(result, s[start:i])
-			start = i
+	if strings.Contains(s, "_") {
+		s = strings.TrimSpace(strings.ReplaceAll(s, "_", " "))
+	}
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	if strings.Contains(s, " ") {
+		return string(runes)
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			switch {
+			case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+				b.WriteByte(' ') // camelCase boundary
+			case unicode.IsUpper(r) && unicode.IsUpper(prev) && nextLower:
+				b.WriteByte(' ') // end of an acronym: HTMLBody -> HTML Body
+			case unicode.IsDigit(r) && !unicode.IsDigit(prev):
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formValue renders a field value for an HTML attribute or textarea.
+func formValue(val any, inputType string) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if inputType == "date" {
+			return v.Format("2006-01-02")
 		}
+		return v.Format("2006-01-02T15:04")
+	}
+	if inputType == "password" {
+		return "" // never echo a password back into the page
 	}
-	result = append(result, s[start:])
-	return strings.Join(result, " ")
+	return template.HTMLEscapeString(fmt.Sprint(val))
 }
 
 func InferInputType(val any) string {
@@ -1,7 +1,10 @@
 package i18n
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,9 +22,19 @@ var (
 	mu               sync.RWMutex
 	currentLocale    = "en"
 	availableLocales []string
+	// sources records which file defined each key, to report collisions.
+	sources = make(map[string]map[string]string)
 )
 
-// Init loads all locale files from the given directory.
+// Init loads all locale files from the given directory. Two layouts are
+// supported and can be mixed:
+//
+//	config/locales/en.yaml          flat: keys as written
+//	config/locales/en/users.yaml    per locale: keys namespaced as users.*
+//	config/locales/en/admin/x.yaml  nested dirs namespace further: admin.x.*
+//
+// Files are loaded in lexical order and a key defined twice keeps the last
+// value; the collision is logged.
 func Init(localesDir string) error {
 	entries, err := os.ReadDir(localesDir)
 	if err != nil {
@@ -36,91 +49,259 @@ func Init(localesDir string) error {
 
 	for _, f := range entries {
 		if f.IsDir() {
+			locale := f.Name()
+			root := filepath.Join(localesDir, locale)
+			err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() || !isYAML(path) {
+					return nil
+				}
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				namespace := strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/", ".")
+				return loadFile(locale, path, namespace)
+			})
+			if err != nil {
+				return err
+			}
 			continue
 		}
-		ext := filepath.Ext(f.Name())
-		if ext != ".yaml" && ext != ".yml" {
+		if !isYAML(f.Name()) {
 			continue
 		}
-
-		locale := strings.TrimSuffix(f.Name(), ext)
-
-		data, err := os.ReadFile(filepath.Join(localesDir, f.Name()))
-		if err != nil {
+		locale := strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
+		if err := loadFile(locale, filepath.Join(localesDir, f.Name()), ""); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		var nested map[string]any
-		if err := yaml.Unmarshal(data, &nested); err != nil {
-			return err
-		}
+// loadFile merges a YAML file into locale's translations under namespace.
+// Callers must hold mu.
+func loadFile(locale, path, namespace string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
 
-		// Locales are optionally nested under the language code: en: { ... }
-		if localeData, ok := nested[locale].(map[string]any); ok {
-			translations[locale] = flatten(localeData, "")
-		} else {
-			translations[locale] = flatten(nested, "")
-		}
+	var nested map[string]any
+	if err := yaml.Unmarshal(data, &nested); err != nil {
+		return fmt.Errorf("i18n: %s: %w", path, err)
+	}
+
+	// Locales are optionally nested under the language code: en: { ... }
+	if localeData, ok := nested[locale].(map[string]any); ok {
+		nested = localeData
+	}
+
+	dest, ok := translations[locale]
+	if !ok {
+		dest = make(map[string]any)
+		translations[locale] = dest
 		availableLocales = append(availableLocales, locale)
 	}
+	for k, v := range flatten(nested, namespace) {
+		if _, exists := dest[k]; exists && sources[locale][k] != path {
+			log.Printf("[Gails] WARN: i18n key %q for locale %q redefined in %s (was %s)", k, locale, path, sources[locale][k])
+		}
+		dest[k] = v
+		if sources[locale] == nil {
+			sources[locale] = make(map[string]string)
+		}
+		sources[locale][k] = path
+	}
 	return nil
 }
 
-// T translates a key with optional variable interpolation.
+func isYAML(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// T translates a key in the global default locale. In request handlers use
+// Context.T (or TFor with the request's locale) so concurrent requests in
+// different languages don't interfere.
 func T(key string, vars Vars) string {
-	mu.RLock()
-	locale := currentLocale
-	mu.RUnlock()
+	return TFor(GetLocale(), key, vars)
+}
 
-	data, ok := translations[locale]
+// TFor translates a key in the given locale, falling back to the default locale.
+func TFor(locale, key string, vars Vars) string {
+	val, ok := lookup(locale, key)
 	if !ok {
-		data, ok = translations[defaultLocale]
-		if !ok {
-			return key
+		return key
+	}
+	return interpolate(val, vars)
+}
+
+// TN translates a key with a plural form chosen by count. Forms live under
+// the key as zero/one/two/few/many/other:
+//
+//	cart:
+//	  items:
+//	    zero: "Your cart is empty"
+//	    one: "1 item"
+//	    other: "%{count} items"
+//
+// "zero" is used for a count of 0 whenever it is defined; otherwise the
+// locale's plural rules pick the category, falling back to "other". count is
+// available as %{count}.
+func TN(key string, count int, vars Vars) string {
+	return TNFor(GetLocale(), key, count, vars)
+}
+
+// TNFor is TN in the given locale.
+func TNFor(locale, key string, count int, vars Vars) string {
+	all := Vars{"count": count}
+	for k, v := range vars {
+		all[k] = v
+	}
+
+	var candidates []string
+	if count == 0 {
+		candidates = append(candidates, "zero")
+	}
+	candidates = append(candidates, pluralCategory(locale, count), "other")
+	for _, form := range candidates {
+		if val, ok := lookup(locale, key+"."+form); ok {
+			return interpolate(val, all)
 		}
 	}
+	// A plain string key works for languages without plural forms.
+	if val, ok := lookup(locale, key); ok {
+		return interpolate(val, all)
+	}
+	return key
+}
 
-	val, ok := data[key].(string)
-	if !ok {
-		// Fallback to default locale
-		if locale != defaultLocale {
-			data, ok = translations[defaultLocale]
-			if ok {
-				val, ok = data[key].(string)
-				if !ok {
-					return key
-				}
-			} else {
-				return key
-			}
-		} else {
-			return key
+// lookup finds key in locale, falling back to the default locale.
+func lookup(locale, key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if val, ok := translations[locale][key].(string); ok {
+		return val, true
+	}
+	if locale != defaultLocale {
+		if val, ok := translations[defaultLocale][key].(string); ok {
+			return val, true
 		}
 	}
+	return "", false
+}
 
+func interpolate(val string, vars Vars) string {
 	for k, v := range vars {
 		placeholder := fmt.Sprintf("%%{%s}", k)
 		val = strings.ReplaceAll(val, placeholder, fmt.Sprint(v))
 	}
-
 	return val
 }
 
-// GetLocale returns the current locale.
+// pluralCategory returns the CLDR plural category for count in locale,
+// covering the common rule families. Unknown locales use English rules.
+func pluralCategory(locale string, count int) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	n := count
+	if n < 0 {
+		n = -n
+	}
+	mod10, mod100 := n%10, n%100
+
+	switch lang {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "tr":
+		return "other"
+	case "fr", "hi", "bn":
+		if n <= 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs", "sk":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		default:
+			return "other"
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		default:
+			return "other"
+		}
+	}
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying a request-scoped locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom returns the locale stored in ctx by WithLocale, or the global
+// default locale.
+func LocaleFrom(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return GetLocale()
+}
+
+// GetLocale returns the global default locale.
 func GetLocale() string {
 	mu.RLock()
 	defer mu.RUnlock()
 	return currentLocale
 }
 
-// SetLocale sets the current locale.
+// SetLocale sets the global default locale. It affects every request that
+// has no locale of its own, so don't call it per request; see WithLocale.
 func SetLocale(locale string) {
 	mu.Lock()
 	defer mu.Unlock()
 	currentLocale = locale
 }
 
-// Locale returns the current locale (alias for GetLocale).
+// Locale returns the global default locale (alias for GetLocale).
 func Locale() string {
 	return GetLocale()
 }
@@ -2,16 +2,60 @@ package framework
 
 import (
 	"context"
+	"net/http"
 	"os"
+	"strconv"
+	"sync/atomic"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var Log *zap.Logger
+// Log is the framework logger. It is never nil: until Boot (or SetLogger)
+// replaces it, it logs to stderr, so code running outside a booted app —
+// tests, the worker, the mailer — still has its logs show up.
+var Log = defaultLogger()
 
+// loggerSet is true once SetLogger has installed a custom logger, which Boot
+// then keeps instead of building its own.
+var loggerSet atomic.Bool
+
+// InitLogger builds the logger for APP_ENV (JSON in production, colored
+// console output otherwise) and installs it.
 func InitLogger() {
-	env := os.Getenv("APP_ENV")
+	logger, err := newLogger(os.Getenv("APP_ENV"))
+	if err != nil {
+		panic(err)
+	}
+	Log = logger
+	zap.ReplaceGlobals(Log)
+}
+
+// SetLogger replaces the framework logger, e.g. to add fields or send logs
+// elsewhere. Call it before Boot: Boot keeps a logger set this way rather
+// than building its own. A nil logger discards everything.
+func SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	loggerSet.Store(true)
+	Log = logger
+	zap.ReplaceGlobals(Log)
+}
+
+// defaultLogger is Log before Boot: the APP_ENV logger, or a no-op logger
+// if it can't be built.
+func defaultLogger() *zap.Logger {
+	logger, err := newLogger(os.Getenv("APP_ENV"))
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger
+}
+
+func newLogger(env string) (*zap.Logger, error) {
 	if env == "" {
 		env = "development"
 	}
@@ -24,14 +68,7 @@ func InitLogger() {
 		config = zap.NewDevelopmentConfig()
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
-
-	var err error
-	Log, err = config.Build()
-	if err != nil {
-		panic(err)
-	}
-
-	zap.ReplaceGlobals(Log)
+	return config.Build()
 }
 
 func FromContext(ctx context.Context) *zap.Logger {
@@ -40,3 +77,28 @@ func FromContext(ctx context.Context) *zap.Logger {
 	}
 	return Log
 }
+
+// requestLogger returns Log tagged with r's request ID and route pattern.
+func requestLogger(r *http.Request) *zap.Logger {
+	fields := []zap.Field{zap.String("request_id", middleware.GetReqID(r.Context()))}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			fields = append(fields, zap.String("route", pattern))
+		}
+	}
+	return Log.With(fields...)
+}
+
+// Logger returns a logger tagged with the request's request_id, route and,
+// once known, user_id, so handler logs line up with the request log line:
+//
+//	ctx.Logger().Info("Order placed", zap.Uint("order_id", order.ID))
+func (c *Context) Logger() *zap.Logger {
+	if c.logger == nil {
+		c.logger = requestLogger(c.Request)
+	}
+	if id, ok := c.currentUserID(); ok {
+		return c.logger.With(zap.String("user_id", strconv.FormatUint(uint64(id), 10)))
+	}
+	return c.logger
+}
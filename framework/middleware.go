@@ -2,12 +2,16 @@ package framework
 
 import (
 	"compress/gzip"
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"io"
+	mathrand "math/rand/v2"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,25 +23,58 @@ import (
 	"go.uber.org/zap"
 )
 
+// LoggerConfig configures LoggerWithConfig.
+type LoggerConfig struct {
+	// SkipPaths are never logged (e.g. "/health", "/metrics").
+	SkipPaths []string
+	// SampleRate logs only this fraction (0–1] of successful requests; 0 logs
+	// all. Responses with status >= 400 are always logged.
+	SampleRate float64
+}
+
 // Logger is structured request logging middleware.
 func Logger() func(http.Handler) http.Handler {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig is structured request logging middleware with path
+// skipping and sampling.
+func LoggerWithConfig(config LoggerConfig) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, p := range config.SkipPaths {
+		skip[p] = true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			start := time.Now()
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			defer func() {
-				if Log != nil {
-					Log.Info("request",
-						zap.String("method", r.Method),
-						zap.String("path", r.URL.Path),
-						zap.Int("status", ww.Status()),
-						zap.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000.0),
-						zap.String("request_id", middleware.GetReqID(r.Context())),
-						zap.String("ip", r.RemoteAddr),
-						zap.String("user_agent", r.UserAgent()),
-					)
+				status := ww.Status()
+				if status < 400 && config.SampleRate > 0 && config.SampleRate < 1 && mathrand.Float64() >= config.SampleRate {
+					return
+				}
+				bytesIn := r.ContentLength
+				if bytesIn < 0 {
+					bytesIn = 0
 				}
+				Log.Info("request",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Int("status", status),
+					zap.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000.0),
+					zap.Int64("bytes_in", bytesIn),
+					zap.Int("bytes_out", ww.BytesWritten()),
+					zap.String("request_id", middleware.GetReqID(r.Context())),
+					zap.String("ip", r.RemoteAddr),
+					zap.String("user_agent", r.UserAgent()),
+				)
 			}()
 
 			next.ServeHTTP(ww, r)
@@ -49,6 +86,8 @@ func Logger() func(http.Handler) http.Handler {
 func Recovery() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Breadcrumbs only matter while the request is in flight.
+			defer GlobalBreadcrumbs.Clear(middleware.GetReqID(r.Context()))
 			defer func() {
 				if err := recover(); err != nil {
 					env := os.Getenv("APP_ENV")
@@ -72,15 +111,110 @@ func RequestID() func(http.Handler) http.Handler {
 	return middleware.RequestID
 }
 
+// MethodOverride lets HTML forms reach PUT, PATCH and DELETE routes. A POST
+// with a `_method` form field (urlencoded forms) or an X-HTTP-Method-Override
+// header is routed as that method instead. It must run before routing, so
+// App.Boot installs it by default.
+func MethodOverride() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				method := r.Header.Get("X-HTTP-Method-Override")
+				if method == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+					method = r.PostFormValue("_method")
+				}
+				switch method = strings.ToUpper(method); method {
+				case http.MethodPut, http.MethodPatch, http.MethodDelete:
+					r.Method = method
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SecurityConfig configures SecureHeadersWithConfig.
+type SecurityConfig struct {
+	// ContentSecurityPolicy is sent as the Content-Security-Policy header. Every
+	// "{nonce}" placeholder is replaced with a fresh per-request nonce, e.g.
+	// "script-src 'self' 'nonce-{nonce}'" (see CSPNonce).
+	ContentSecurityPolicy string
+	// HSTSMaxAge enables Strict-Transport-Security with this max-age, in seconds.
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	// FrameSameOrigin sends X-Frame-Options: SAMEORIGIN instead of DENY.
+	FrameSameOrigin bool
+}
+
+type cspNonceKey struct{}
+
 // SecureHeaders adds security-related HTTP headers.
 func SecureHeaders(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("X-XSS-Protection", "1; mode=block")
-		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		next.ServeHTTP(w, r)
-	})
+	return SecureHeadersWithConfig(SecurityConfig{})(next)
+}
+
+// SecureHeadersWithConfig adds security-related HTTP headers, including an
+// optional Content-Security-Policy and HSTS.
+func SecureHeadersWithConfig(config SecurityConfig) func(http.Handler) http.Handler {
+	frameOptions := "DENY"
+	if config.FrameSameOrigin {
+		frameOptions = "SAMEORIGIN"
+	}
+
+	var hsts string
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(config.HSTSMaxAge)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	useNonce := strings.Contains(config.ContentSecurityPolicy, "{nonce}")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("X-Frame-Options", frameOptions)
+			w.Header().Set("X-XSS-Protection", "1; mode=block")
+			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			if hsts != "" {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+
+			if csp := config.ContentSecurityPolicy; csp != "" {
+				if useNonce {
+					nonce := generateCSPNonce()
+					csp = strings.ReplaceAll(csp, "{nonce}", nonce)
+					r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+				}
+				w.Header().Set("Content-Security-Policy", csp)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CSPNonce returns the request's Content-Security-Policy nonce, or "" if the
+// policy has none. Context.Render exposes it to templates as .CSPNonce:
+//
+//	<script nonce="{{.CSPNonce}}">...</script>
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+// CSPNonce returns the request's Content-Security-Policy nonce (see framework.CSPNonce).
+func (c *Context) CSPNonce() string {
+	return CSPNonce(c.Request)
+}
+
+func generateCSPNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
 }
 
 // CORSConfig configures CORS behavior.
@@ -129,13 +263,28 @@ func CORS(config CORSConfig) func(http.Handler) http.Handler {
 	}
 }
 
+// CSRFConfig configures CSRFWithConfig.
+type CSRFConfig struct {
+	// EnforceJSON also requires a token (X-CSRF-Token header) on JSON requests.
+	// Enable it when a permissive CORS policy lets other origins send JSON.
+	EnforceJSON bool
+	// Secure marks the token cookie Secure so it is only sent over HTTPS.
+	Secure bool
+}
+
 // CSRF implements double-submit cookie CSRF protection, skipped for JSON requests.
+// The token cookie is marked Secure in production.
 func CSRF() func(http.Handler) http.Handler {
+	return CSRFWithConfig(CSRFConfig{Secure: os.Getenv("APP_ENV") == "production"})
+}
+
+// CSRFWithConfig implements double-submit cookie CSRF protection.
+func CSRFWithConfig(config CSRFConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip CSRF for JSON API requests
+			// Skip CSRF for JSON API requests unless enforced
 			ct := r.Header.Get("Content-Type")
-			if strings.Contains(ct, "application/json") {
+			if !config.EnforceJSON && strings.Contains(ct, "application/json") {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -150,8 +299,11 @@ func CSRF() func(http.Handler) http.Handler {
 						Value:    token,
 						Path:     "/",
 						HttpOnly: false, // Readable by JS for AJAX
+						Secure:   config.Secure,
 						SameSite: http.SameSiteLaxMode,
 					})
+					// Forms rendered on this request need the new token.
+					r = r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, token))
 				}
 				next.ServeHTTP(w, r)
 				return
@@ -164,12 +316,12 @@ func CSRF() func(http.Handler) http.Handler {
 				return
 			}
 
-			formToken := r.FormValue("csrf_token")
-			if formToken == "" {
-				formToken = r.Header.Get("X-CSRF-Token")
+			formToken := r.Header.Get("X-CSRF-Token")
+			if formToken == "" && !strings.Contains(ct, "application/json") {
+				formToken = r.FormValue("csrf_token")
 			}
 
-			if formToken != cookie.Value {
+			if formToken == "" || subtle.ConstantTimeCompare([]byte(formToken), []byte(cookie.Value)) != 1 {
 				http.Error(w, "CSRF token invalid", http.StatusForbidden)
 				return
 			}
@@ -179,76 +331,266 @@ func CSRF() func(http.Handler) http.Handler {
 	}
 }
 
+type csrfTokenKey struct{}
+
+// CSRFToken returns the token forms must submit as csrf_token, or "" if the
+// CSRF middleware isn't in use. Context.Render exposes it to templates as
+// .CSRFToken:
+//
+//	{{formFor .Post "/posts" "POST" .Errors .CSRFToken}}
+//	<form method="post">{{csrfToken .CSRFToken}}...</form>
+func CSRFToken(r *http.Request) string {
+	if token, ok := r.Context().Value(csrfTokenKey{}).(string); ok {
+		return token
+	}
+	if cookie, err := r.Cookie("csrf_token"); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// CSRFToken returns the request's CSRF token (see framework.CSRFToken).
+func (c *Context) CSRFToken() string {
+	return CSRFToken(c.Request)
+}
+
 func generateCSRFToken() string {
 	b := make([]byte, 32)
 	rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
-// gzipResponseWriter wraps http.ResponseWriter with gzip compression.
-type gzipResponseWriter struct {
-	io.Writer
-	http.ResponseWriter
+// CompressConfig configures CompressWithConfig.
+type CompressConfig struct {
+	// MinLength is the smallest body worth compressing, in bytes (default 1024).
+	MinLength int
+	// SkipContentTypes lists Content-Type prefixes that are sent uncompressed.
+	// Defaults to already-compressed media (images, video, audio, archives).
+	SkipContentTypes []string
 }
 
-func (w gzipResponseWriter) Write(b []byte) (int, error) {
-	return w.Writer.Write(b)
+var defaultSkipContentTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"application/x-7z-compressed", "application/x-rar-compressed",
+	"application/pdf", "application/octet-stream",
+	"text/event-stream", // many proxies and clients mishandle compressed SSE
 }
 
 // Compress applies gzip compression for responses over a threshold.
 func Compress() func(http.Handler) http.Handler {
+	return CompressWithConfig(CompressConfig{})
+}
+
+// CompressWithConfig applies gzip compression to compressible responses of at
+// least MinLength bytes. Bodiless responses (204, 304, HEAD) and responses that
+// already carry a Content-Encoding are passed through untouched.
+func CompressWithConfig(config CompressConfig) func(http.Handler) http.Handler {
+	if config.MinLength <= 0 {
+		config.MinLength = 1024
+	}
+	if config.SkipContentTypes == nil {
+		config.SkipContentTypes = defaultSkipContentTypes
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			gz, err := gzip.NewWriterLevel(w, gzip.DefaultCompression)
-			if err != nil {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || r.Method == http.MethodHead {
 				next.ServeHTTP(w, r)
 				return
 			}
-			defer gz.Close()
 
-			w.Header().Set("Content-Encoding", "gzip")
-			w.Header().Del("Content-Length")
-			next.ServeHTTP(gzipResponseWriter{Writer: gz, ResponseWriter: w}, r)
+			gw := &gzipResponseWriter{ResponseWriter: w, config: config}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
 		})
 	}
 }
 
-// RateLimit implements Redis-backed sliding window rate limiting.
+// gzipResponseWriter buffers the start of a response until it can decide
+// whether compression is worthwhile, then sets headers and streams through gzip.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	config   CompressConfig
+	gz       *gzip.Writer
+	buf      []byte
+	status   int
+	decided  bool
+	compress bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code) // informational, final status still to come
+		return
+	}
+	w.status = code
+	if !bodyAllowed(code) {
+		w.decide(false)
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.config.MinLength {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.compress {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush commits to a decision so streamed responses aren't held in the buffer.
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.decide(true)
+	}
+	if w.compress {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes out any buffered body and finishes the gzip stream.
+func (w *gzipResponseWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return nil // handler wrote nothing; let net/http send its default
+		}
+		w.decide(len(w.buf) >= w.config.MinLength)
+	}
+	if w.compress {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide sends the headers, compressing only if wanted and the content allows
+// it, then writes out the buffered body.
+func (w *gzipResponseWriter) decide(want bool) error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	w.compress = want && bodyAllowed(w.status) && h.Get("Content-Encoding") == "" &&
+		w.compressible(h.Get("Content-Type"))
+
+	if w.compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.compress {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *gzipResponseWriter) compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "image/svg") {
+		return true
+	}
+	for _, prefix := range w.config.SkipContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyAllowed reports whether a response with the given status may have a body.
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// RateLimitConfig configures RateLimitWithConfig.
+type RateLimitConfig struct {
+	Limit  int
+	Window time.Duration
+	// Name namespaces the limiter's counters so route-specific limiters don't share buckets.
+	Name string
+	// KeyFunc identifies who a request counts against (e.g. a user ID). Defaults to ClientIP.
+	KeyFunc func(r *http.Request) string
+}
+
+// RateLimit implements Redis-backed sliding window rate limiting keyed by client IP.
 func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	return RateLimitWithConfig(RateLimitConfig{Limit: limit, Window: window})
+}
+
+// RateLimitWithConfig implements sliding window rate limiting, backed by Redis when
+// available. Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers.
+func RateLimitWithConfig(config RateLimitConfig) func(http.Handler) http.Handler {
+	if config.KeyFunc == nil {
+		config.KeyFunc = ClientIP
+	}
+	prefix := "ratelimit:"
+	if config.Name != "" {
+		prefix += config.Name + ":"
+	}
+	limit, window := config.Limit, config.Window
+
 	// Fallback in-memory rate limiter when Redis is not available
-	var mu sync.Mutex
-	counts := make(map[string][]time.Time)
+	memory := newMemoryLimiter(window)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if cache.Redis != nil {
-				redisRateLimit(w, r, next, limit, window)
-				return
-			}
+			key := prefix + config.KeyFunc(r)
 
-			// In-memory fallback
-			mu.Lock()
-			ip := r.RemoteAddr
-			now := time.Now()
-			cutoff := now.Add(-window)
-
-			// Clean old entries
-			var valid []time.Time
-			for _, t := range counts[ip] {
-				if t.After(cutoff) {
-					valid = append(valid, t)
+			var count int
+			var reset time.Time
+			if cache.Redis != nil {
+				var err error
+				count, reset, err = redisRateLimit(r.Context(), key, window)
+				if err != nil {
+					Log.Error("Rate limit error", zap.Error(err))
+					next.ServeHTTP(w, r)
+					return
 				}
+			} else {
+				// In-memory fallback
+				count, reset = memory.hit(key, time.Now())
 			}
-			valid = append(valid, now)
-			counts[ip] = valid
-			count := len(valid)
-			mu.Unlock()
 
+			setRateLimitHeaders(w, limit, count, reset)
 			if count > limit {
 				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(window.Seconds())))
 				w.WriteHeader(http.StatusTooManyRequests)
@@ -261,40 +603,149 @@ func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler
 	}
 }
 
-func redisRateLimit(w http.ResponseWriter, r *http.Request, next http.Handler, limit int, window time.Duration) {
-	ctx := r.Context()
-	key := fmt.Sprintf("ratelimit:%s", r.RemoteAddr)
+// memoryLimiter counts hits per key in a sliding window, in process memory.
+type memoryLimiter struct {
+	window   time.Duration
+	mu       sync.Mutex
+	counts   map[string][]time.Time
+	sweeper  sync.Once
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newMemoryLimiter(window time.Duration) *memoryLimiter {
+	return &memoryLimiter{window: window, counts: make(map[string][]time.Time), done: make(chan struct{})}
+}
+
+// sweepingLimiters holds the limiters whose sweeper is running, so the app
+// can stop them on shutdown.
+var (
+	sweepingMu       sync.Mutex
+	sweepingLimiters = make(map[*memoryLimiter]struct{})
+)
+
+// stopMemoryLimiters stops every running limiter sweeper. New registers it
+// as an OnShutdown hook.
+func stopMemoryLimiters(ctx context.Context) error {
+	sweepingMu.Lock()
+	limiters := make([]*memoryLimiter, 0, len(sweepingLimiters))
+	for l := range sweepingLimiters {
+		limiters = append(limiters, l)
+	}
+	sweepingMu.Unlock()
+	for _, l := range limiters {
+		l.stop()
+	}
+	return nil
+}
+
+// stop ends the limiter's sweeper goroutine. It is safe to call more than once.
+func (l *memoryLimiter) stop() {
+	l.stopOnce.Do(func() { close(l.done) })
+	sweepingMu.Lock()
+	delete(sweepingLimiters, l)
+	sweepingMu.Unlock()
+}
+
+// hit records a hit for key at now and returns the hit count within the
+// window and when the oldest counted hit expires.
+func (l *memoryLimiter) hit(key string, now time.Time) (int, time.Time) {
+	l.sweeper.Do(l.startSweeper)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cutoff := now.Add(-l.window)
+
+	// Clean old entries
+	var valid []time.Time
+	for _, t := range l.counts[key] {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	valid = append(valid, now)
+	l.counts[key] = valid
+	return len(valid), valid[0].Add(l.window)
+}
 
+// startSweeper starts sweepEvery unless the limiter is already stopped.
+func (l *memoryLimiter) startSweeper() {
+	if l.window <= 0 {
+		return
+	}
+	sweepingMu.Lock()
+	defer sweepingMu.Unlock()
+	select {
+	case <-l.done:
+		return
+	default:
+	}
+	sweepingLimiters[l] = struct{}{}
+	go l.sweepEvery()
+}
+
+// sweepEvery sweeps once per window, so clients that stop sending requests
+// don't stay in memory forever. It returns when the limiter is stopped.
+func (l *memoryLimiter) sweepEvery() {
+	ticker := time.NewTicker(l.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			l.sweep(now)
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// sweep drops keys whose newest hit has left the window.
+func (l *memoryLimiter) sweep(now time.Time) {
+	cutoff := now.Add(-l.window)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, hits := range l.counts {
+		if len(hits) == 0 || !hits[len(hits)-1].After(cutoff) {
+			delete(l.counts, key)
+		}
+	}
+}
+
+// redisRateLimit records a hit for key and returns the hit count within the
+// window and when the oldest counted hit expires.
+func redisRateLimit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
 	now := time.Now().UnixNano()
 	clearBefore := now - window.Nanoseconds()
 
 	pipe := cache.Redis.Pipeline()
 	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", clearBefore))
 	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now), Member: fmt.Sprintf("%d", now)})
-	pipe.ZCard(ctx, key)
+	card := pipe.ZCard(ctx, key)
+	oldest := pipe.ZRangeWithScores(ctx, key, 0, 0)
 	pipe.Expire(ctx, key, window)
 
-	cmds, err := pipe.Exec(ctx)
-	if err != nil {
-		if Log != nil {
-			Log.Error("Rate limit error", zap.Error(err))
-		}
-		next.ServeHTTP(w, r)
-		return
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, time.Time{}, err
 	}
 
-	count := cmds[2].(*redis.IntCmd).Val()
-	if count > int64(limit) {
-		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(window.Seconds())))
-		w.WriteHeader(http.StatusTooManyRequests)
-		w.Write([]byte("Rate limit exceeded"))
-		return
+	reset := time.Unix(0, now).Add(window)
+	if z := oldest.Val(); len(z) > 0 {
+		reset = time.Unix(0, int64(z[0].Score)).Add(window)
 	}
+	return int(card.Val()), reset, nil
+}
 
-	next.ServeHTTP(w, r)
+func setRateLimitHeaders(w http.ResponseWriter, limit, count int, reset time.Time) {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
 }
 
-// Locale detects the user locale from query param, session, or Accept-Language.
+// Locale detects the user locale from query param or Accept-Language and
+// stores it on the request context (see Context.Locale and Context.T).
 func Locale(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// 1. Check query param ?locale=
@@ -313,7 +764,7 @@ func Locale(next http.Handler) http.Handler {
 		}
 
 		if lang != "" {
-			i18n.SetLocale(lang)
+			r = r.WithContext(i18n.WithLocale(r.Context(), lang))
 		}
 
 		next.ServeHTTP(w, r)
@@ -0,0 +1,74 @@
+package framework
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterEvictsIdleClients(t *testing.T) {
+	window := 50 * time.Millisecond
+	l := newMemoryLimiter(window)
+	defer l.stop()
+	l.hit("a", time.Now())
+	l.hit("b", time.Now())
+	if n := l.len(); n != 2 {
+		t.Fatalf("limiter tracks %d clients, want 2", n)
+	}
+
+	// The background sweeper runs once per window.
+	deadline := time.Now().Add(20 * window)
+	for l.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(window / 2)
+	}
+	if n := l.len(); n != 0 {
+		t.Errorf("limiter still tracks %d clients after the window, want 0", n)
+	}
+}
+
+func TestMemoryLimiterSweepKeepsActiveClients(t *testing.T) {
+	l := newMemoryLimiter(time.Minute)
+	defer l.stop()
+	now := time.Now()
+	l.hit("idle", now.Add(-2*time.Minute))
+	l.hit("active", now.Add(-time.Second))
+
+	l.sweep(now)
+	if _, ok := l.counts["idle"]; ok {
+		t.Error("idle client was not evicted")
+	}
+	if count, _ := l.hit("active", now); count != 2 {
+		t.Errorf("active client count = %d, want 2", count)
+	}
+}
+
+func TestStopMemoryLimitersEndsSweepers(t *testing.T) {
+	l := newMemoryLimiter(time.Minute)
+	l.hit("a", time.Now())
+
+	sweepingMu.Lock()
+	_, running := sweepingLimiters[l]
+	sweepingMu.Unlock()
+	if !running {
+		t.Fatal("limiter sweeper was not registered")
+	}
+
+	stopMemoryLimiters(context.Background())
+	select {
+	case <-l.done:
+	default:
+		t.Fatal("limiter was not stopped")
+	}
+	sweepingMu.Lock()
+	_, running = sweepingLimiters[l]
+	sweepingMu.Unlock()
+	if running {
+		t.Error("stopped limiter is still registered")
+	}
+}
+
+func (l *memoryLimiter) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.counts)
+}
@@ -20,6 +20,15 @@ type PluginWithMigrations interface {
 	Migrations() []string // Returns paths or SQL migration content
 }
 
+// PluginWithShutdown is a plugin that holds resources (connections,
+// goroutines, buffers) to release when the app stops. App.Run calls Shutdown
+// after the HTTP server and the OnShutdown hooks have drained, in reverse boot
+// order, before the database and Redis are closed.
+type PluginWithShutdown interface {
+	Plugin
+	Shutdown(app *App) error
+}
+
 // MiddlewareProvider is a plugin that can inject global middleware.
 type MiddlewareProvider interface {
 	Plugin
@@ -5,8 +5,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 )
 
 // --- Prometheus Metrics ---
@@ -80,6 +83,35 @@ func RecordHTTPRequest(method, path string, status int, duration time.Duration)
 	httpRequestDuration.WithLabelValues(method, path).Observe(duration.Seconds())
 }
 
+// Metrics records request count and latency for every request. Requests are
+// labelled by their chi route pattern (e.g. /users/{id}) rather than the raw
+// path, keeping label cardinality bounded.
+func Metrics() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			RecordHTTPRequest(r.Method, routePattern(r), status, time.Since(start))
+		})
+	}
+}
+
+// routePattern returns the matched chi route pattern, or "unmatched" for 404s.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
 // RecordDBQuery records a metric for a database query.
 func RecordDBQuery(duration time.Duration) {
 	dbQueryDuration.Observe(duration.Seconds())
@@ -160,3 +192,78 @@ func (br *BreadcrumbRing) Clear(requestID string) {
 	defer br.mu.Unlock()
 	delete(br.entries, requestID)
 }
+
+// metricsHandler is MetricsHandler behind the configured basic auth, if any.
+func (a *App) metricsHandler() http.Handler {
+	h := MetricsHandler()
+	if auth := a.Config.Metrics.BasicAuth; auth.Username != "" {
+		h = BasicAuth(auth.Username, auth.Password)(h)
+	}
+	return h
+}
+
+// metricsPath is where metrics are served, /metrics by default.
+func (a *App) metricsPath() string {
+	if a.Config.Metrics.Path != "" {
+		return a.Config.Metrics.Path
+	}
+	return "/metrics"
+}
+
+// mountMetrics mounts the metrics endpoint on the app's router, unless it is
+// disabled or served on its own listener (see startMetricsServer).
+func (a *App) mountMetrics() {
+	cfg := a.Config.Metrics
+	if !cfg.IsEnabled() || cfg.Addr != "" {
+		return
+	}
+	path := a.metricsPath()
+	if cfg.BasicAuth.Username == "" && a.Config.App.Env == "production" {
+		Log.Warn("Metrics are served without authentication; set metrics.basic_auth or metrics.addr",
+			zap.String("path", path))
+	}
+	a.Router.Mux.Handle(path, a.metricsHandler())
+	a.Router.addRoute("GET", path, "Prometheus")
+}
+
+// startMetricsServer serves metrics on metrics.addr, if set. It returns nil
+// when metrics share the app's listener.
+func (a *App) startMetricsServer() *http.Server {
+	cfg := a.Config.Metrics
+	if !cfg.IsEnabled() || cfg.Addr == "" {
+		return nil
+	}
+	path := a.metricsPath()
+	mux := http.NewServeMux()
+	mux.Handle(path, a.metricsHandler())
+	server := &http.Server{Addr: cfg.Addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			Log.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+	Log.Info("Serving metrics", zap.String("addr", cfg.Addr), zap.String("path", path))
+	return server
+}
+
+// mountProfiler mounts net/http/pprof (and expvar at /debug/vars) under
+// /debug when profiling is enabled. Only development serves them without
+// basic auth; elsewhere they are left unmounted, and config validation
+// refuses to boot a production app that enables them without it.
+func (a *App) mountProfiler() {
+	cfg := a.Config.Profiling
+	if !cfg.Enabled {
+		return
+	}
+
+	var h http.Handler = middleware.Profiler()
+	if cfg.BasicAuth.Username != "" {
+		h = BasicAuth(cfg.BasicAuth.Username, cfg.BasicAuth.Password)(h)
+	} else if a.Config.App.Env != "development" {
+		Log.Warn("Profiling not mounted: profiling.basic_auth is required outside development")
+		return
+	}
+	a.Router.Mux.Mount("/debug", h)
+	a.Router.addRoute("GET", "/debug/pprof/*", "pprof")
+	Log.Info("Profiling enabled at /debug/pprof/")
+}
@@ -0,0 +1,173 @@
+package framework
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// QueryCounterConfig configures the development query counter.
+type QueryCounterConfig struct {
+	// Threshold logs a warning when a request runs more queries than this (default 20).
+	Threshold int
+	// RepeatThreshold flags a likely N+1 when one query shape runs this many times (default 5).
+	RepeatThreshold int
+	// Header adds an X-Query-Count response header.
+	Header bool
+}
+
+// QueryStats tracks the SQL queries executed during a single request.
+type QueryStats struct {
+	mu     sync.Mutex
+	count  int
+	shapes map[string]int
+}
+
+type queryStatsKey struct{}
+
+// Count returns the number of queries executed so far.
+func (s *QueryStats) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Repeated returns query shapes executed at least min times.
+func (s *QueryStats) Repeated(min int) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repeated := make(map[string]int)
+	for sql, n := range s.shapes {
+		if n >= min {
+			repeated[sql] = n
+		}
+	}
+	return repeated
+}
+
+func (s *QueryStats) record(sql string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.shapes[sql]++
+}
+
+// QueryStatsFromContext returns the query stats for a request, if counting is enabled.
+func QueryStatsFromContext(ctx context.Context) *QueryStats {
+	stats, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return stats
+}
+
+// RegisterQueryCounter installs GORM callbacks that count queries against the
+// QueryStats on the statement's context. Queries must be bound to the request
+// context (see Context.DB) to be counted.
+func RegisterQueryCounter(db *gorm.DB) {
+	count := func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+		if stats := QueryStatsFromContext(tx.Statement.Context); stats != nil {
+			stats.record(tx.Statement.SQL.String())
+		}
+	}
+
+	cb := db.Callback()
+	cb.Create().After("gorm:create").Register("gails:query_counter", count)
+	cb.Query().After("gorm:query").Register("gails:query_counter", count)
+	cb.Update().After("gorm:update").Register("gails:query_counter", count)
+	cb.Delete().After("gorm:delete").Register("gails:query_counter", count)
+	cb.Row().After("gorm:row").Register("gails:query_counter", count)
+	cb.Raw().After("gorm:raw").Register("gails:query_counter", count)
+}
+
+// QueryCounter counts DB queries per request and warns about excessive or
+// repeated (likely N+1) queries. It is a no-op in production.
+func QueryCounter(config QueryCounterConfig) func(http.Handler) http.Handler {
+	if config.Threshold <= 0 {
+		config.Threshold = 20
+	}
+	if config.RepeatThreshold <= 0 {
+		config.RepeatThreshold = 5
+	}
+
+	return func(next http.Handler) http.Handler {
+		if os.Getenv("APP_ENV") == "production" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stats := &QueryStats{shapes: make(map[string]int)}
+			r = r.WithContext(context.WithValue(r.Context(), queryStatsKey{}, stats))
+
+			if config.Header {
+				w = &queryCountWriter{ResponseWriter: w, stats: stats}
+			}
+			next.ServeHTTP(w, r)
+
+			reqID := middleware.GetReqID(r.Context())
+			if n := stats.Count(); n > config.Threshold {
+				Log.Warn("Too many queries for request",
+					zap.String("path", r.URL.Path),
+					zap.Int("queries", n),
+					zap.Int("threshold", config.Threshold),
+					zap.String("request_id", reqID),
+				)
+			}
+			for sql, n := range stats.Repeated(config.RepeatThreshold) {
+				Log.Warn("Possible N+1 query detected",
+					zap.String("path", r.URL.Path),
+					zap.String("sql", sql),
+					zap.Int("times", n),
+					zap.String("request_id", reqID),
+				)
+			}
+		})
+	}
+}
+
+// queryCountWriter adds the X-Query-Count header just before the response is written.
+type queryCountWriter struct {
+	http.ResponseWriter
+	stats       *QueryStats
+	wroteHeader bool
+}
+
+func (w *queryCountWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("X-Query-Count", strconv.Itoa(w.stats.Count()))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *queryCountWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *queryCountWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades through, which take over the connection
+// without a response.
+func (w *queryCountWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, for
+// deadlines and the like.
+func (w *queryCountWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,102 @@
+package framework
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	trustedMu      sync.RWMutex
+	trustedProxies = mustParseCIDRs(
+		"127.0.0.0/8", "::1/128", // loopback
+		"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7", // private networks
+	)
+)
+
+// SetTrustedProxies replaces the networks whose X-Forwarded-For / X-Real-IP
+// headers are trusted by ClientIP. Pass no arguments to trust no proxy.
+// Defaults to loopback and private network ranges.
+func SetTrustedProxies(cidrs ...string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, n)
+	}
+	trustedMu.Lock()
+	trustedProxies = nets
+	trustedMu.Unlock()
+	return nil
+}
+
+// ClientIP returns the originating client IP for a request. Forwarding headers
+// are only honored when the direct peer is a trusted proxy; X-Forwarded-For is
+// walked right-to-left, skipping trusted hops, so clients can't spoof it.
+func ClientIP(r *http.Request) string {
+	remote := remoteHost(r.RemoteAddr)
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if !isTrustedProxy(ip) || i == 0 {
+				return ip
+			}
+		}
+	}
+
+	if xrip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xrip) != nil {
+		return xrip
+	}
+	return remote
+}
+
+// ClientIP returns the originating client IP for the request (see framework.ClientIP).
+func (c *Context) ClientIP() string {
+	return ClientIP(c.Request)
+}
+
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	trustedMu.RLock()
+	defer trustedMu.RUnlock()
+	for _, n := range trustedProxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
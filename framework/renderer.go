@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/shaurya/gails/config"
@@ -20,6 +22,12 @@ type Renderer struct {
 	Config    *config.Config
 	mu        sync.RWMutex
 	compiled  bool
+	// base is a never-executed copy of Templates; html/template can only
+	// Clone a template before it runs, so RenderLocale clones this one.
+	base *template.Template
+	// locales caches RenderLocale's per-locale clones of base. Guarded by
+	// mu and reset by CompileTemplates.
+	locales map[string]*template.Template
 }
 
 // NewRenderer creates a new Renderer.
@@ -43,7 +51,7 @@ func (r *Renderer) CompileTemplates() {
 		filepath.Walk(viewsDir, func(path string, info os.FileInfo, err error) error {
 			if err == nil && !info.IsDir() && filepath.Ext(path) == ".html" {
 				_, parseErr := tmpl.ParseFiles(path)
-				if parseErr != nil && Log != nil {
+				if parseErr != nil {
 					Log.Warn(fmt.Sprintf("Failed to parse template %s: %v", path, parseErr))
 				}
 			}
@@ -52,6 +60,8 @@ func (r *Renderer) CompileTemplates() {
 	}
 
 	r.Templates = tmpl
+	r.base = template.Must(tmpl.Clone())
+	r.locales = nil
 	r.compiled = true
 }
 
@@ -64,12 +74,10 @@ func (r *Renderer) templateFuncs() template.FuncMap {
 		"linkTo": func(text, url string) template.HTML {
 			return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, url, text))
 		},
-		"csrfToken": func() template.HTML {
-			return template.HTML(`<input type="hidden" name="csrf_token" value="token">`)
-		},
-		"flashMessages": func() template.HTML {
-			return template.HTML("")
-		},
+		// {{csrfToken .CSRFToken}}; Context.Render sets .CSRFToken.
+		"csrfToken": helpers.CSRFField,
+		// {{flashMessages .Flash}}; Context.Render sets .Flash from the session.
+		"flashMessages": flashMessages,
 		"currentUser": func() any {
 			return nil
 		},
@@ -92,18 +100,20 @@ func (r *Renderer) templateFuncs() template.FuncMap {
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
+		// t and tn use the global locale here; RenderLocale rebinds them to
+		// the request's.
 		"t": func(key string, args ...any) string {
-			vars := make(i18n.Vars)
-			if len(args) == 1 {
-				vars["name"] = args[0]
-			} else {
-				for idx := 0; idx < len(args)-1; idx += 2 {
-					if k, ok := args[idx].(string); ok {
-						vars[k] = args[idx+1]
-					}
-				}
-			}
-			return i18n.T(key, vars)
+			return i18n.T(key, templateVars(args))
+		},
+		"tn": func(key string, count int, args ...any) string {
+			return i18n.TN(key, count, templateVars(args))
+		},
+		// Request-locale variants; Context.Render sets .Locale: {{tFor .Locale "key"}}
+		"tFor": func(locale, key string, args ...any) string {
+			return i18n.TFor(locale, key, templateVars(args))
+		},
+		"tnFor": func(locale, key string, count int, args ...any) string {
+			return i18n.TNFor(locale, key, count, templateVars(args))
 		},
 		"stylesheetInclude": assets.StylesheetTag,
 		"javascriptInclude": assets.JavascriptTag,
@@ -135,3 +145,97 @@ func (r *Renderer) Render(w io.Writer, name string, data any) error {
 
 	return r.Templates.ExecuteTemplate(w, name, data)
 }
+
+// RenderLocale is Render with the t and tn helpers translating into locale
+// rather than the global default. Context.Render calls it with the request's
+// locale.
+func (r *Renderer) RenderLocale(w io.Writer, name string, data any, locale string) error {
+	if locale == "" || locale == i18n.GetLocale() {
+		return r.Render(w, name, data)
+	}
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+	if env != "production" {
+		r.CompileTemplates()
+	}
+
+	tmpl, err := r.localeTemplates(locale)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// localeTemplates returns the templates with t and tn bound to locale,
+// cloning them from base the first time a locale is rendered.
+func (r *Renderer) localeTemplates(locale string) (*template.Template, error) {
+	r.mu.RLock()
+	tmpl := r.locales[locale]
+	r.mu.RUnlock()
+	if tmpl != nil {
+		return tmpl, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tmpl := r.locales[locale]; tmpl != nil {
+		return tmpl, nil
+	}
+	if r.base == nil {
+		return nil, fmt.Errorf("templates not compiled")
+	}
+	tmpl, err := r.base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"t": func(key string, args ...any) string {
+			return i18n.TFor(locale, key, templateVars(args))
+		},
+		"tn": func(key string, count int, args ...any) string {
+			return i18n.TNFor(locale, key, count, templateVars(args))
+		},
+	})
+	if r.locales == nil {
+		r.locales = make(map[string]*template.Template)
+	}
+	r.locales[locale] = tmpl
+	return tmpl, nil
+}
+
+// templateVars turns template arguments into interpolation variables: either
+// a single value (bound to %{name}) or key/value pairs.
+func templateVars(args []any) i18n.Vars {
+	vars := make(i18n.Vars)
+	if len(args) == 1 {
+		vars["name"] = args[0]
+	} else {
+		for idx := 0; idx < len(args)-1; idx += 2 {
+			if k, ok := args[idx].(string); ok {
+				vars[k] = args[idx+1]
+			}
+		}
+	}
+	return vars
+}
+
+// flashMessages renders each flash as <div class="flash flash-{type}">.
+func flashMessages(flashes ...map[string][]string) template.HTML {
+	var b strings.Builder
+	for _, set := range flashes {
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, msg := range set[key] {
+				fmt.Fprintf(&b, `<div class="flash flash-%s">%s</div>`,
+					template.HTMLEscapeString(key), template.HTMLEscapeString(msg))
+			}
+		}
+	}
+	return template.HTML(b.String())
+}
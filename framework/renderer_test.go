@@ -0,0 +1,79 @@
+package framework
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaurya/gails/framework/i18n"
+)
+
+func TestRenderLocaleTranslatesIntoRequestLocale(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("views/hello.html", `{{define "hello"}}{{t "greeting"}}{{end}}`)
+	writeFile("locales/en.yaml", "greeting: Hello\n")
+	writeFile("locales/fr.yaml", "greeting: Bonjour\n")
+	if err := i18n.Init(filepath.Join(dir, "locales")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	// Production compiles once, so the templates have already been executed
+	// by the time a second locale is rendered.
+	t.Setenv("APP_ENV", "production")
+
+	r := NewRenderer(nil)
+	for _, locale := range []string{"en", "fr", "en", "fr"} {
+		var b strings.Builder
+		if err := r.RenderLocale(&b, "hello", nil, locale); err != nil {
+			t.Fatalf("RenderLocale(%q): %v", locale, err)
+		}
+		want := map[string]string{"en": "Hello", "fr": "Bonjour"}[locale]
+		if b.String() != want {
+			t.Errorf("RenderLocale(%q) = %q, want %q", locale, b.String(), want)
+		}
+	}
+	if n := len(r.locales); n != 1 {
+		t.Errorf("cached %d locale template sets, want 1 (fr; en is the default)", n)
+	}
+}
+
+func TestBindValidatesInRequestLocale(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("errors:\n  validations:\n    required: \"%{field} est obligatoire\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := i18n.Init(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var errs map[string][]string
+	h := Wrap(func(c *Context) error {
+		var in struct {
+			Name string `json:"name" validate:"required"`
+		}
+		err := c.Bind(&in)
+		if httpErr, ok := err.(*HTTPError); ok {
+			errs = httpErr.Errors
+		}
+		return nil
+	})
+	req := httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(i18n.WithLocale(req.Context(), "fr"))
+	h(httptest.NewRecorder(), req)
+
+	if got := errs["name"]; len(got) != 1 || got[0] != "name est obligatoire" {
+		t.Errorf("errors = %v, want name: [name est obligatoire]", errs)
+	}
+}
@@ -0,0 +1,155 @@
+package framework
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mimeFormats maps media types to the format names used by Respond.
+var mimeFormats = map[string]string{
+	"application/json":      "json",
+	"text/html":             "html",
+	"application/xhtml+xml": "html",
+	"text/csv":              "csv",
+	"application/xml":       "xml",
+	"text/xml":              "xml",
+	"text/plain":            "text",
+}
+
+// Respond dispatches to the handler for the format the client asked for,
+// like Rails' respond_to. The format comes from ?format=, then a path
+// extension (/posts.json), then the Accept header. Requests with no
+// preference (no Accept header, or */*) get HTML for HTMX and JSON otherwise,
+// falling back to whatever the action offers. If none of the client's
+// formats are offered, the response is 406 Not Acceptable.
+//
+//	return ctx.Respond(map[string]func() error{
+//		"html": func() error { return ctx.Render("posts/index.html", framework.H{"Posts": posts}) },
+//		"json": func() error { return ctx.JSON(http.StatusOK, posts) },
+//	})
+func (c *Context) Respond(handlers map[string]func() error) error {
+	c.Response.Header().Add("Vary", "Accept")
+	if handler, ok := handlers[c.negotiate(handlers)]; ok {
+		return handler()
+	}
+	return &HTTPError{Code: http.StatusNotAcceptable, ErrorCode: "not_acceptable", Message: "Not Acceptable"}
+}
+
+// Format returns the response format the client prefers, from ?format=, a
+// path extension or the Accept header, or "" if it expressed no preference.
+func (c *Context) Format() string {
+	if f := c.explicitFormat(); f != "" {
+		return f
+	}
+	for _, mediaType := range acceptedTypes(c.Request.Header.Get("Accept")) {
+		if f, ok := mimeFormats[mediaType]; ok {
+			return f
+		}
+	}
+	return ""
+}
+
+// explicitFormat returns the format named by ?format= or the path extension.
+func (c *Context) explicitFormat() string {
+	if f := c.Query("format"); f != "" {
+		return strings.ToLower(f)
+	}
+	if ext := path.Ext(c.Request.URL.Path); ext != "" {
+		if f := strings.ToLower(ext[1:]); isKnownFormat(f) {
+			return f
+		}
+	}
+	return ""
+}
+
+// negotiate picks the best offered format, or "" if there is none.
+func (c *Context) negotiate(handlers map[string]func() error) string {
+	if f := c.explicitFormat(); f != "" {
+		return f
+	}
+	types := acceptedTypes(c.Request.Header.Get("Accept"))
+	if len(types) == 0 {
+		return c.defaultFormat(handlers)
+	}
+	for _, mediaType := range types {
+		if mediaType == "*/*" {
+			return c.defaultFormat(handlers)
+		}
+		if f, ok := mimeFormats[mediaType]; ok {
+			if _, offered := handlers[f]; offered {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+// defaultFormat picks a format for clients without a preference.
+func (c *Context) defaultFormat(handlers map[string]func() error) string {
+	order := []string{"json", "html"}
+	if c.IsHTMX() {
+		order = []string{"html", "json"}
+	}
+	for _, f := range order {
+		if _, ok := handlers[f]; ok {
+			return f
+		}
+	}
+	// Neither offered (e.g. just "csv"): serve the first by name.
+	offered := make([]string, 0, len(handlers))
+	for f := range handlers {
+		offered = append(offered, f)
+	}
+	sort.Strings(offered)
+	if len(offered) > 0 {
+		return offered[0]
+	}
+	return ""
+}
+
+func isKnownFormat(f string) bool {
+	for _, known := range mimeFormats {
+		if known == f {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedTypes returns the media types in an Accept header, most preferred
+// first. Types with q=0 are dropped.
+func acceptedTypes(accept string) []string {
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+	var types []accepted
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && k == "q" {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			types = append(types, accepted{mediaType, q})
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].q > types[j].q })
+
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = t.mediaType
+	}
+	return out
+}
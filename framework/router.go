@@ -3,97 +3,180 @@ package framework
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"runtime"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // RouteInfo stores metadata about a registered route for the route inspector.
 type RouteInfo struct {
-	Method  string
-	Path    string
-	Handler string
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+	// Middleware lists the middleware the route runs through, outermost first.
+	Middleware []string `json:"middleware"`
 }
 
 // Router wraps chi.Mux with Rails-like conventions.
 type Router struct {
 	Mux    *chi.Mux
 	app    *App
-	routes []RouteInfo
-	prefix string
+	routes *[]RouteInfo // shared by the whole router tree
+	// prefix is the full path of routes added through this router, as
+	// reported in RouteInfo. Mux is mounted below it, at prefix minus base,
+	// so patterns are registered on Mux as base+path.
+	prefix     string
+	base       string
+	inline     bool // Mux is shared with the parent router; see Use
+	api        *apiVersions
+	middleware []string // names of the middleware applied so far, for the inspector
 }
 
 // NewRouter creates a new Router.
 func NewRouter() *Router {
 	return &Router{
 		Mux:    chi.NewRouter(),
-		routes: make([]RouteInfo, 0),
+		routes: &[]RouteInfo{},
+		api:    newAPIVersions(),
+	}
+}
+
+// ServeHTTP dispatches the request, resolving unversioned API paths first.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.app != nil {
+		r.app.mountRoutes()
+	}
+	r.Mux.ServeHTTP(w, r.api.negotiate(req, func(path string) bool {
+		return r.Mux.Match(chi.NewRouteContext(), req.Method, path)
+	}))
+}
+
+// subRouter creates a child Router sharing this router's state.
+func (r *Router) subRouter(prefix string) *Router {
+	return &Router{
+		Mux:    chi.NewRouter(),
+		app:    r.app,
+		routes: r.routes,
+		prefix: prefix,
+		api:    r.api,
+		// Sub-routers are mounted on this one, so they inherit its middleware.
+		middleware: append([]string(nil), r.middleware...),
 	}
 }
 
 func (r *Router) addRoute(method, path, handler string) {
 	fullPath := r.prefix + path
-	r.routes = append(r.routes, RouteInfo{Method: method, Path: fullPath, Handler: handler})
+	*r.routes = append(*r.routes, RouteInfo{
+		Method:     method,
+		Path:       fullPath,
+		Handler:    handler,
+		Middleware: append([]string(nil), r.middleware...),
+	})
 }
 
 // Use adds middleware to the router.
 func (r *Router) Use(mw func(http.Handler) http.Handler) {
-	r.Mux.Use(mw)
+	if r.inline {
+		// Scope mw to the routes added from here on, leaving the shared
+		// Mux's other routes alone.
+		r.Mux = r.Mux.With(mw).(*chi.Mux)
+	} else {
+		r.Mux.Use(mw)
+	}
+	r.middleware = append(r.middleware, funcName(mw))
 }
 
 // GET registers a GET route.
 func (r *Router) GET(path string, handler Action) {
 	r.addRoute("GET", path, actionName(handler))
-	r.Mux.Get(r.prefix+path, ActionHandler(handler, r.app))
+	r.Mux.Get(r.base+path, ActionHandler(handler, r.app))
 }
 
 // POST registers a POST route.
 func (r *Router) POST(path string, handler Action) {
 	r.addRoute("POST", path, actionName(handler))
-	r.Mux.Post(r.prefix+path, ActionHandler(handler, r.app))
+	r.Mux.Post(r.base+path, ActionHandler(handler, r.app))
 }
 
 // PUT registers a PUT route.
 func (r *Router) PUT(path string, handler Action) {
 	r.addRoute("PUT", path, actionName(handler))
-	r.Mux.Put(r.prefix+path, ActionHandler(handler, r.app))
+	r.Mux.Put(r.base+path, ActionHandler(handler, r.app))
 }
 
 // PATCH registers a PATCH route.
 func (r *Router) PATCH(path string, handler Action) {
 	r.addRoute("PATCH", path, actionName(handler))
-	r.Mux.Patch(r.prefix+path, ActionHandler(handler, r.app))
+	r.Mux.Patch(r.base+path, ActionHandler(handler, r.app))
 }
 
 // DELETE registers a DELETE route.
 func (r *Router) DELETE(path string, handler Action) {
 	r.addRoute("DELETE", path, actionName(handler))
-	r.Mux.Delete(r.prefix+path, ActionHandler(handler, r.app))
+	r.Mux.Delete(r.base+path, ActionHandler(handler, r.app))
 }
 
 // Mount mounts a sub-handler at a prefix.
 func (r *Router) Mount(path string, handler http.Handler) {
 	r.addRoute("*", path+"/*", "Mounted Handler")
-	r.Mux.Mount(path, handler)
+	r.Mux.Mount(r.base+path, handler)
 }
 
 // WebSocket registers a WebSocket route.
 func (r *Router) WebSocket(path string, handler http.HandlerFunc) {
 	r.addRoute("WS", path, "WebSocket")
-	r.Mux.Get(path, handler)
+	r.Mux.Get(r.base+path, handler)
 }
 
-// Namespace creates a sub-group with a prefix.
-func (r *Router) Namespace(prefix string, fn func(r *Router)) {
-	subRouter := &Router{
-		Mux:    chi.NewRouter(),
-		app:    r.app,
-		routes: r.routes,
-		prefix: r.prefix + prefix,
+// Namespace creates a sub-group with a prefix. Middleware passed to it
+// applies only to the group's routes:
+//
+//	r.Namespace("/admin", func(r *framework.Router) {
+//		r.Resources("users", &admin.UsersController{})
+//	}, auth.JWTMiddleware())
+func (r *Router) Namespace(prefix string, fn func(r *Router), mws ...func(http.Handler) http.Handler) {
+	r.namespace(prefix, fn, mws...)
+}
+
+// Group registers the routes added by fn with extra middleware, without
+// changing their paths.
+func (r *Router) Group(fn func(r *Router), mws ...func(http.Handler) http.Handler) {
+	fn(r.With(mws...))
+}
+
+// With returns a router whose routes also run mws, for scoping middleware to
+// a single route or resource:
+//
+//	r.With(auth.JWTMiddleware()).Resources("posts", &PostsController{})
+//	r.With(RateLimit(10, time.Minute)).POST("/login", sessions.Create)
+func (r *Router) With(mws ...func(http.Handler) http.Handler) *Router {
+	inline := &Router{
+		Mux:        r.Mux.With(mws...).(*chi.Mux),
+		app:        r.app,
+		routes:     r.routes,
+		prefix:     r.prefix,
+		base:       r.base,
+		inline:     true,
+		api:        r.api,
+		middleware: append([]string(nil), r.middleware...),
+	}
+	for _, mw := range mws {
+		inline.middleware = append(inline.middleware, funcName(mw))
+	}
+	return inline
+}
+
+// namespace mounts a sub-router at prefix, applying mws to its routes only.
+func (r *Router) namespace(prefix string, fn func(r *Router), mws ...func(http.Handler) http.Handler) {
+	subRouter := r.subRouter(r.prefix + prefix)
+	for _, mw := range mws {
+		subRouter.Use(mw)
 	}
 	fn(subRouter)
-	r.routes = subRouter.routes
-	r.Mux.Mount(prefix, subRouter.Mux)
+	r.Mux.Mount(r.base+prefix, subRouter.Mux)
 }
 
 // Resources registers RESTful routes for a controller.
@@ -111,7 +194,7 @@ func (r *Router) Resources(name string, controller any, fn ...func(r *Router)) {
 		controllerName = parts[len(parts)-1]
 	}
 
-	r.Mux.Route(prefix, func(router chi.Router) {
+	r.Mux.Route(r.base+prefix, func(router chi.Router) {
 		app := r.app
 
 		// Index: GET /resources
@@ -152,43 +235,88 @@ func (r *Router) Resources(name string, controller any, fn ...func(r *Router)) {
 			router.Delete("/{id}", ActionHandler(c.Destroy, app))
 		}
 
-		// Nested resources
+		// Nested resources. They are registered on this resource's mux under
+		// /{name_id} rather than mounted there: a mount would also claim
+		// /{name_id} itself and shadow Show.
 		if len(fn) > 0 {
-			nestedRouter := &Router{
-				Mux:    chi.NewRouter(),
-				app:    app,
-				routes: r.routes,
-				prefix: prefix + "/{" + singleName(name) + "_id}",
-			}
+			member := "/{" + singleName(name) + "_id}"
+			nestedRouter := r.subRouter(r.prefix + prefix + member)
+			nestedRouter.Mux = router.(*chi.Mux)
+			nestedRouter.base = member
+			nestedRouter.inline = true
 			fn[0](nestedRouter)
-			r.routes = nestedRouter.routes
-			router.Mount("/{"+singleName(name)+"_id}", nestedRouter.Mux)
 		}
 	})
 }
 
 // Inspect returns all registered routes as a formatted table.
 func (r *Router) Inspect() string {
-	var sb strings.Builder
+	return r.inspect(false)
+}
 
-	// Header
-	sb.WriteString("┌────────────┬────────────────────────────────┬────────────────────────────────┐\n")
-	sb.WriteString("│ Method     │ Path                           │ Handler                        │\n")
-	sb.WriteString("├────────────┼────────────────────────────────┼────────────────────────────────┤\n")
+// InspectMiddleware is like Inspect, with a column listing each route's
+// middleware stack.
+func (r *Router) InspectMiddleware() string {
+	return r.inspect(true)
+}
 
-	for _, route := range r.routes {
-		sb.WriteString(fmt.Sprintf("│ %-10s │ %-30s │ %-30s │\n", route.Method, route.Path, route.Handler))
+func (r *Router) inspect(withMiddleware bool) string {
+	header := []string{"Method", "Path", "Handler"}
+	if withMiddleware {
+		header = append(header, "Middleware")
+	}
+	routes := *r.routes
+	rows := make([][]string, len(routes))
+	for i, route := range routes {
+		rows[i] = []string{route.Method, route.Path, route.Handler}
+		if withMiddleware {
+			rows[i] = append(rows[i], strings.Join(route.Middleware, ", "))
+		}
+	}
+
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = max(utf8.RuneCountInString(h), 10)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var sb strings.Builder
+	border := func(left, mid, right string) {
+		sb.WriteString(left)
+		for i, w := range widths {
+			if i > 0 {
+				sb.WriteString(mid)
+			}
+			sb.WriteString(strings.Repeat("─", w+2))
+		}
+		sb.WriteString(right + "\n")
+	}
+	line := func(cells []string) {
+		for i, cell := range cells {
+			sb.WriteString(fmt.Sprintf("│ %-*s ", widths[i], cell))
+		}
+		sb.WriteString("│\n")
 	}
 
-	sb.WriteString("└────────────┴────────────────────────────────┴────────────────────────────────┘\n")
-	sb.WriteString(fmt.Sprintf("Total: %d routes\n", len(r.routes)))
+	border("┌", "┬", "┐")
+	line(header)
+	border("├", "┼", "┤")
+	for _, row := range rows {
+		line(row)
+	}
+	border("└", "┴", "┘")
+	sb.WriteString(fmt.Sprintf("Total: %d routes\n", len(routes)))
 
 	return sb.String()
 }
 
 // GetRoutes returns all registered route info.
 func (r *Router) GetRoutes() []RouteInfo {
-	return r.routes
+	return *r.routes
 }
 
 // singleName converts a plural resource name to singular (basic singularization).
@@ -205,7 +333,54 @@ func singleName(name string) string {
 	return name
 }
 
-// actionName returns a string name for a handler function (for route table display).
-func actionName(_ Action) string {
-	return "Action"
+// actionName returns a string name for a handler function (for route table
+// display): "PostsController#Index" for controller methods, "pkg.Func" for
+// plain functions.
+func actionName(handler Action) string {
+	name := funcName(handler)
+	// Method values are named pkg.(*PostsController).Index-fm or
+	// pkg.PostsController.Index-fm.
+	if fn := runtimeName(handler); strings.HasSuffix(fn, "-fm") {
+		parts := strings.Split(name, ".")
+		if len(parts) >= 3 {
+			recv := strings.Trim(parts[len(parts)-2], "(*)")
+			return recv + "#" + parts[len(parts)-1]
+		}
+	}
+	return name
+}
+
+// funcName returns fn's name as pkg.Func, without the import path. Closures
+// are named after the function that created them (framework.CORS.func1
+// becomes framework.CORS).
+func funcName(fn any) string {
+	name := runtimeName(fn)
+	if name == "" {
+		return "Action"
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	// Strip closure suffixes: .func1, .func1.2, and .1 for inlined copies.
+	for {
+		i := strings.LastIndex(name, ".")
+		if i < 0 || strings.Trim(strings.TrimPrefix(name[i+1:], "func"), "0123456789") != "" {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
+
+// runtimeName returns the fully qualified name the runtime gives fn.
+func runtimeName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
 }
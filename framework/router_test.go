@@ -0,0 +1,66 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type testUsersController struct{}
+
+func (testUsersController) Index(c *Context) error {
+	return c.JSON(http.StatusOK, H{"action": "users"})
+}
+
+func (testUsersController) Show(c *Context) error {
+	return c.JSON(http.StatusOK, H{"action": "user " + c.Param("id")})
+}
+
+type testPostsController struct{}
+
+func (testPostsController) Index(c *Context) error {
+	return c.JSON(http.StatusOK, H{"action": "posts of " + c.Param("user_id")})
+}
+
+func TestNestedNamespaces(t *testing.T) {
+	r := NewRouter()
+	r.Namespace("/api", func(r *Router) {
+		r.Namespace("/v1", func(r *Router) {
+			r.Resources("users", &testUsersController{}, func(r *Router) {
+				r.Resources("posts", &testPostsController{})
+			})
+		})
+	})
+
+	var paths []string
+	for _, route := range r.GetRoutes() {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	for _, want := range []string{
+		"GET /api/v1/users",
+		"GET /api/v1/users/{id}",
+		"GET /api/v1/users/{user_id}/posts",
+	} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("GetRoutes() = %v, missing %q", paths, want)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/users", `{"action":"users"}`},
+		{"/api/v1/users/7", `{"action":"user 7"}`},
+		{"/api/v1/users/7/posts", `{"action":"posts of 7"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != tt.want {
+			t.Errorf("GET %s = %d %q, want 200 %q", tt.path, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package framework
+
+import (
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SPAFallback serves a single-page app from dir for every request no route
+// matched: existing files are served as-is and any other GET falls back to
+// dir/index.html so client-side routing survives a refresh. Paths under one of
+// apiPrefixes still return 404, so API clients never receive HTML.
+//
+//	r.SPAFallback("frontend/dist", []string{"/api"})
+//
+// Register it on the root router.
+func (r *Router) SPAFallback(dir string, apiPrefixes []string) {
+	root := http.Dir(dir)
+	files := http.FileServer(root)
+	index := filepath.Join(dir, "index.html")
+
+	r.addRoute("GET", "/*", "SPA Fallback")
+	r.Mux.NotFound(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.NotFound(w, req)
+			return
+		}
+
+		for _, prefix := range apiPrefixes {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/") {
+				http.NotFound(w, req)
+				return
+			}
+		}
+
+		if f, err := root.Open(path.Clean("/" + req.URL.Path)); err == nil {
+			stat, statErr := f.Stat()
+			f.Close()
+			if statErr == nil && !stat.IsDir() {
+				files.ServeHTTP(w, req)
+				return
+			}
+		}
+
+		// The shell must always be revalidated so deploys take effect immediately.
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFile(w, req, index)
+	})
+}
@@ -0,0 +1,126 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Stream sends a 200 response whose body is written incrementally by fn.
+// Every write is flushed to the client, so large exports never sit in memory
+// and Compress still applies (it flushes instead of buffering).
+//
+//	return ctx.Stream("text/csv", func(w io.Writer) error {
+//		cw := csv.NewWriter(w)
+//		for rows.Next() { ... cw.Write(record); cw.Flush() }
+//		return cw.Error()
+//	})
+//
+// Once streaming has started the status can't change, so an error returned
+// by fn is logged rather than sent.
+func (c *Context) Stream(contentType string, fn func(w io.Writer) error) error {
+	c.cacheTTL = 0 // never cache a partial or unbounded body
+	h := c.Response.Header()
+	h.Set("Content-Type", contentType)
+	h.Del("Content-Length")
+	h.Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+
+	rc := http.NewResponseController(c.Response)
+	// Long-lived streams outlast the server's WriteTimeout.
+	rc.SetWriteDeadline(time.Time{})
+
+	c.Response.WriteHeader(http.StatusOK)
+	c.statusCode = http.StatusOK
+	c.written = true
+	rc.Flush()
+
+	return fn(&flushWriter{w: c.Response, rc: rc})
+}
+
+// flushWriter flushes after every write.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err == nil {
+		err = fw.rc.Flush()
+		if err == http.ErrNotSupported {
+			err = nil
+		}
+	}
+	return n, err
+}
+
+// SSEStream writes server-sent events; see Context.SSE.
+type SSEStream struct {
+	w    io.Writer
+	done <-chan struct{}
+}
+
+// SSE streams server-sent events (text/event-stream) from fn until it returns
+// or the client disconnects (Done is closed).
+//
+//	return ctx.SSE(func(s *framework.SSEStream) error {
+//		for {
+//			select {
+//			case <-s.Done():
+//				return nil
+//			case msg := <-updates:
+//				if err := s.Send("update", msg); err != nil {
+//					return err
+//				}
+//			}
+//		}
+//	})
+func (c *Context) SSE(fn func(s *SSEStream) error) error {
+	c.Response.Header().Set("Cache-Control", "no-cache")
+	return c.Stream("text/event-stream", func(w io.Writer) error {
+		return fn(&SSEStream{w: w, done: c.Request.Context().Done()})
+	})
+}
+
+// Send writes one event. Strings and []byte are sent as is, anything else is
+// JSON-encoded; an empty event name sends an unnamed "message" event.
+func (s *SSEStream) Send(event string, data any) error {
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		payload = string(b)
+	}
+
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(payload, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+// Comment writes an SSE comment line, e.g. as a keep-alive ping.
+func (s *SSEStream) Comment(text string) error {
+	_, err := fmt.Fprintf(s.w, ": %s\n\n", text)
+	return err
+}
+
+// Done is closed when the client disconnects.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
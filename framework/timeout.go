@@ -0,0 +1,247 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// timeoutErrorKey holds the *HTTPError sent for requests that time out, so
+// actions that give up with context.DeadlineExceeded send the same response.
+type timeoutErrorKey struct{}
+
+// TimeoutConfig configures TimeoutWithConfig.
+type TimeoutConfig struct {
+	Timeout time.Duration
+	// Status is sent if the handler hasn't responded by the deadline
+	// (default 503 Service Unavailable).
+	Status int
+	// Message is the timeout response's message (default "Request timed out").
+	Message string
+}
+
+// Timeout bounds each request to d. The request context carries the
+// deadline, so ctx.DB() queries are cancelled when it passes; a handler that
+// hasn't started responding by then gets a 503 sent on its behalf, and its
+// later writes fail with http.ErrHandlerTimeout.
+//
+// WebSocket upgrades and event-stream requests are not timed out. Other
+// long-lived responses (Context.Stream) are cut off by the deadline, so
+// route them outside Timeout, e.g. with Router.With.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig is Timeout with a configurable response.
+func TimeoutWithConfig(config TimeoutConfig) func(http.Handler) http.Handler {
+	if config.Status == 0 {
+		config.Status = http.StatusServiceUnavailable
+	}
+	if config.Message == "" {
+		config.Message = "Request timed out"
+	}
+
+	timeoutErr := &HTTPError{Code: config.Status, Message: config.Message}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if config.Timeout <= 0 || isLongLived(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), config.Timeout)
+			defer cancel()
+			ctx = context.WithValue(ctx, timeoutErrorKey{}, timeoutErr)
+			r = r.WithContext(detachRouteContext(ctx))
+
+			tw := &timeoutWriter{w: w, h: w.Header().Clone()}
+			done := make(chan struct{})
+			panicked := make(chan *PanicError, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						pe := &PanicError{Value: p, Stack: debug.Stack()}
+						// Hand the panic to the request goroutine to re-raise
+						// where Recovery can see it, unless that has already
+						// given up on the handler. The check and send share
+						// tw.mu with the give-up, so every panic is reported
+						// one way or the other.
+						tw.mu.Lock()
+						defer tw.mu.Unlock()
+						if tw.timedOut {
+							Log.Error("Panic after request timed out",
+								zap.String("path", r.URL.Path), zap.Any("panic", p), zap.ByteString("stack", pe.Stack))
+							return
+						}
+						panicked <- pe
+						return
+					}
+					close(done)
+				}()
+				next.ServeHTTP(tw, r)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.finish()
+			case <-ctx.Done():
+				tw.mu.Lock()
+				if tw.wroteHeader {
+					// The response is under way and its status can't change;
+					// let the handler see the cancelled context and wind down.
+					tw.mu.Unlock()
+					select {
+					case p := <-panicked:
+						panic(p)
+					case <-done:
+					}
+					return
+				}
+				tw.timedOut = true
+				tw.mu.Unlock()
+				select {
+				case p := <-panicked:
+					panic(p) // it beat the deadline
+				default:
+				}
+				if ctx.Err() == context.DeadlineExceeded {
+					handleActionError(NewContext(w, r, nil), timeoutErr)
+				}
+			}
+		})
+	}
+}
+
+// PanicError is a panic recovered on another goroutine and re-raised on the
+// request's, as Timeout does. Stack is the stack of the goroutine that
+// panicked; the error handlers report it instead of their own.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint(e.Value)
+}
+
+// Unwrap returns the panic value if it was an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// detachRouteContext gives ctx its own copy of chi's routing context. Chi
+// recycles the original once the middleware returns, which a handler still
+// running past the deadline would otherwise race with.
+func detachRouteContext(ctx context.Context) context.Context {
+	rctx := chi.RouteContext(ctx)
+	if rctx == nil {
+		return ctx
+	}
+	cp := chi.NewRouteContext()
+	cp.Routes = rctx.Routes
+	cp.RoutePath = rctx.RoutePath
+	cp.RouteMethod = rctx.RouteMethod
+	cp.URLParams.Keys = slices.Clone(rctx.URLParams.Keys)
+	cp.URLParams.Values = slices.Clone(rctx.URLParams.Values)
+	cp.RoutePatterns = slices.Clone(rctx.RoutePatterns)
+	return context.WithValue(ctx, chi.RouteCtxKey, cp)
+}
+
+// isLongLived reports whether r is a WebSocket upgrade or an SSE request.
+func isLongLived(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// timeoutWriter keeps its own header map until the handler responds, so a
+// handler still running after the timeout can't race with the 503.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	h           http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeader(http.StatusOK)
+	}
+	return tw.w.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	if !tw.wroteHeader {
+		tw.writeHeader(http.StatusOK)
+	}
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// writeHeader copies the handler's headers to the real writer and sends them.
+func (tw *timeoutWriter) writeHeader(code int) {
+	tw.copyHeader()
+	if code >= 100 && code < 200 {
+		tw.w.WriteHeader(code) // informational, final status still to come
+		return
+	}
+	tw.wroteHeader = true
+	tw.w.WriteHeader(code)
+}
+
+// finish hands the headers over if the handler returned without writing.
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.wroteHeader {
+		tw.copyHeader()
+	}
+}
+
+func (tw *timeoutWriter) copyHeader() {
+	dst := tw.w.Header()
+	clear(dst)
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,32 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func panickingHandler(w http.ResponseWriter, r *http.Request) {
+	panic("boom")
+}
+
+func TestTimeoutRepanicsWithHandlerStack(t *testing.T) {
+	h := Timeout(time.Second)(http.HandlerFunc(panickingHandler))
+
+	defer func() {
+		pe, ok := recover().(*PanicError)
+		if !ok {
+			t.Fatalf("recovered %T, want *PanicError", pe)
+		}
+		if pe.Value != "boom" {
+			t.Errorf("Value = %v, want boom", pe.Value)
+		}
+		if !strings.Contains(string(pe.Stack), "panickingHandler") {
+			t.Errorf("Stack doesn't include the handler:\n%s", pe.Stack)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	t.Fatal("ServeHTTP returned without panicking")
+}
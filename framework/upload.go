@@ -0,0 +1,190 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultMaxUploadSize   int64 = 32 << 20 // 32 MB
+	defaultMaxUploadMemory int64 = 8 << 20  // 8 MB
+)
+
+// uploadLimits returns the configured upload limits, falling back to defaults.
+func (c *Context) uploadLimits() (maxSize, maxMemory int64) {
+	maxSize, maxMemory = defaultMaxUploadSize, defaultMaxUploadMemory
+	if c.app != nil && c.app.Config != nil {
+		if c.app.Config.Uploads.MaxSize > 0 {
+			maxSize = c.app.Config.Uploads.MaxSize
+		}
+		if c.app.Config.Uploads.MaxMemory > 0 {
+			maxMemory = c.app.Config.Uploads.MaxMemory
+		}
+	}
+	if maxMemory > maxSize {
+		maxMemory = maxSize
+	}
+	return maxSize, maxMemory
+}
+
+// IsMultipart returns true if the request Content-Type is multipart/form-data.
+func (c *Context) IsMultipart() bool {
+	return strings.HasPrefix(c.Request.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// MultipartForm parses the multipart request body, enforcing the configured
+// max upload size across all parts. Parts over max_memory spill to
+// os.TempDir ($TMPDIR) and are removed automatically once the request
+// completes.
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	if c.Request.MultipartForm != nil {
+		return c.Request.MultipartForm, nil
+	}
+
+	maxSize, maxMemory := c.uploadLimits()
+	c.Request.Body = http.MaxBytesReader(c.Response, c.Request.Body, maxSize)
+
+	err := c.Request.ParseMultipartForm(maxMemory)
+	if c.Request.MultipartForm != nil {
+		c.onCleanup(func() { c.Request.MultipartForm.RemoveAll() })
+	}
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, &HTTPError{Code: http.StatusRequestEntityTooLarge, Message: "Upload exceeds maximum size"}
+		}
+		return nil, err
+	}
+	return c.Request.MultipartForm, nil
+}
+
+// FormFile returns the first file uploaded in the multipart field name, or
+// http.ErrMissingFile. The body is limited to the configured max upload size.
+//
+//	fh, err := ctx.FormFile("avatar")
+//	if err != nil {
+//		return ctx.BadRequest(err)
+//	}
+//	if err := framework.ValidateUpload(fh, 2<<20, "image/png", "image/jpeg"); err != nil {
+//		return ctx.UnprocessableEntity(map[string][]string{"avatar": {err.Error()}})
+//	}
+//	return ctx.SaveUploadedFile(fh, "public/uploads/avatars/"+name)
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	files, err := c.FormFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// FormFiles returns every file uploaded in the multipart field name
+// (an <input type="file" multiple>), or http.ErrMissingFile.
+func (c *Context) FormFiles(name string) ([]*multipart.FileHeader, error) {
+	if !c.IsMultipart() {
+		return nil, http.ErrNotMultipart
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files, nil
+}
+
+// SaveUploadedFile writes an uploaded file to dst, creating its directory.
+// dst is used as is, so never build it from the client's filename unchecked.
+func (c *Context) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ValidateUpload checks an uploaded file's size and type. The type is sniffed
+// from the file's content rather than trusted from the client; allowed types
+// may end in a wildcard ("image/*"). A maxSize of 0 or no types skip that check.
+func ValidateUpload(fh *multipart.FileHeader, maxSize int64, allowedTypes ...string) error {
+	if maxSize > 0 && fh.Size > maxSize {
+		return fmt.Errorf("must be at most %s", formatBytes(maxSize))
+	}
+	if len(allowedTypes) == 0 {
+		return nil
+	}
+	contentType, err := DetectUploadType(fh)
+	if err != nil {
+		return err
+	}
+	for _, allowed := range allowedTypes {
+		if allowed == contentType ||
+			(strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*"))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(allowedTypes, ", "))
+}
+
+// DetectUploadType sniffs an uploaded file's content type from its first
+// 512 bytes, ignoring parameters such as charset.
+func DetectUploadType(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return contentType, nil
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%d GB", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// onCleanup registers a function to run after the action completes.
+func (c *Context) onCleanup(fn func()) {
+	c.cleanups = append(c.cleanups, fn)
+}
+
+// cleanup runs all registered cleanup functions in reverse order.
+func (c *Context) cleanup() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+	c.cleanups = nil
+}
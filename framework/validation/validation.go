@@ -0,0 +1,157 @@
+// Package validation holds the validator shared by request binding and
+// model validation, so both report the same field names and messages.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shaurya/gails/framework/i18n"
+)
+
+var (
+	validate = newValidator()
+
+	mu       sync.RWMutex
+	messages = map[string]string{}
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// Report fields by the name clients send (json, then query tag) rather
+	// than the Go field name.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "query"} {
+			name := strings.Split(f.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return ""
+	})
+	return v
+}
+
+// Validator returns the shared validator, e.g. to register custom rules:
+//
+//	validation.Validator().RegisterValidation("slug", isSlug)
+func Validator() *validator.Validate {
+	return validate
+}
+
+// SetMessage overrides the message for a validation tag. The template may use
+// %{field} and %{param}:
+//
+//	validation.SetMessage("min", "%{field} must be at least %{param} characters")
+//
+// A field can also override its messages with an `errmsg:"..."` struct tag.
+func SetMessage(tag, template string) {
+	mu.Lock()
+	defer mu.Unlock()
+	messages[tag] = template
+}
+
+// Struct validates s and returns field errors keyed by field name, or nil.
+// A non-validation error (e.g. s isn't a struct) is returned as the second value.
+// Messages are translated into the global locale.
+func Struct(s any) (map[string][]string, error) {
+	return StructFor(i18n.GetLocale(), s)
+}
+
+// StructFor is Struct with messages translated into locale, e.g. the
+// request's.
+func StructFor(locale string, s any) (map[string][]string, error) {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil, nil
+	}
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+	root := reflect.TypeOf(s)
+	errs := make(map[string][]string)
+	for _, e := range verrs {
+		errs[e.Field()] = append(errs[e.Field()], message(locale, root, e))
+	}
+	return errs, nil
+}
+
+// message picks, in order: the field's errmsg tag, a SetMessage template, an
+// "errors.validations.<tag>" translation, then a generic fallback.
+func message(locale string, root reflect.Type, e validator.FieldError) string {
+	field := e.Field()
+	vars := i18n.Vars{"field": field, "param": e.Param()}
+
+	if f, ok := structField(root, e.StructNamespace()); ok {
+		if tmpl := f.Tag.Get("errmsg"); tmpl != "" {
+			return interpolate(tmpl, vars)
+		}
+	}
+
+	mu.RLock()
+	tmpl, ok := messages[e.Tag()]
+	mu.RUnlock()
+	if ok {
+		return interpolate(tmpl, vars)
+	}
+
+	key := "errors.validations." + e.Tag()
+	if label := i18n.TFor(locale, "models.fields."+e.StructField(), nil); label != "models.fields."+e.StructField() {
+		vars["field"] = label
+	}
+	if msg := i18n.TFor(locale, key, vars); msg != key {
+		return msg
+	}
+
+	if e.Param() != "" {
+		return fmt.Sprintf("%s must be %s %s", field, e.Tag(), e.Param())
+	}
+	return fmt.Sprintf("%s is invalid (%s)", field, e.Tag())
+}
+
+func interpolate(tmpl string, vars i18n.Vars) string {
+	for k, v := range vars {
+		tmpl = strings.ReplaceAll(tmpl, "%{"+k+"}", fmt.Sprint(v))
+	}
+	return tmpl
+}
+
+// structField resolves a namespace like "User.Address[0].Street" to its
+// struct field, so per-field tags can be read.
+func structField(root reflect.Type, namespace string) (reflect.StructField, bool) {
+	parts := strings.Split(namespace, ".")
+	if len(parts) < 2 {
+		return reflect.StructField{}, false
+	}
+	t := root
+	var f reflect.StructField
+	for _, part := range parts[1:] {
+		t = elemType(t)
+		if t.Kind() != reflect.Struct {
+			return reflect.StructField{}, false
+		}
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			part = part[:i]
+		}
+		var ok bool
+		if f, ok = t.FieldByName(part); !ok {
+			return reflect.StructField{}, false
+		}
+		t = f.Type
+	}
+	return f, true
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t
+}
@@ -3,16 +3,22 @@ package generator
 import (
 	"bytes"
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
+
+	"github.com/jinzhu/inflection"
 )
 
 // Generator handles code generation from templates.
 type Generator struct {
 	TemplatesDir string
+	// Force allows existing files to be overwritten. Without it, files that
+	// already exist are skipped so hand-edited code is never clobbered.
+	Force bool
 }
 
 // NewGenerator creates a new Generator.
@@ -26,6 +32,8 @@ type Field struct {
 	Type        string
 	GormTag     string
 	ValidateTag string
+	// References is the table a references/belongs_to field points at.
+	References string
 }
 
 // TypeMap maps field type shorthand to Go types.
@@ -40,6 +48,9 @@ var TypeMap = map[string]string{
 	"date":     "time.Time",
 	"datetime": "time.Time",
 	"uuid":     "string",
+	// Foreign keys: user:references becomes UserID referencing users(id).
+	"references": "uint",
+	"belongs_to": "uint",
 }
 
 // ParseFields parses field definitions from CLI arguments.
@@ -61,9 +72,14 @@ func (g *Generator) ParseFields(args []string) []Field {
 		}
 
 		f := Field{
-			Name: capitalize(name),
+			Name: PascalCase(name),
 			Type: goType,
 		}
+		if typeName == "references" || typeName == "belongs_to" {
+			f.Name = PascalCase(name + "_id")
+			f.References = Pluralize(SnakeCase(name))
+			f.GormTag = "index"
+		}
 
 		// Handle modifiers
 		if len(parts) > 2 {
@@ -82,6 +98,9 @@ func (g *Generator) ParseFields(args []string) []Field {
 				}
 			}
 			if len(gormTags) > 0 {
+				if f.GormTag != "" {
+					gormTags = append([]string{f.GormTag}, gormTags...)
+				}
 				f.GormTag = strings.Join(gormTags, ";")
 			}
 			if len(valTags) > 0 {
@@ -97,15 +116,7 @@ func (g *Generator) ParseFields(args []string) []Field {
 func (g *Generator) Generate(templateName string, data any, targetPath string) error {
 	tmplPath := filepath.Join(g.TemplatesDir, templateName+".tmpl")
 
-	funcMap := template.FuncMap{
-		"Title":     strings.Title,
-		"Lower":     strings.ToLower,
-		"Plural":    pluralize,
-		"Singular":  singularize,
-		"Timestamp": func() string { return time.Now().Format("20060102150405") },
-	}
-
-	tmpl := template.New(templateName + ".tmpl").Funcs(funcMap)
+	tmpl := template.New(templateName + ".tmpl").Funcs(funcMap())
 	tmpl, err := tmpl.ParseFiles(tmplPath)
 	if err != nil {
 		return fmt.Errorf("failed to parse template %s: %w", tmplPath, err)
@@ -116,25 +127,12 @@ func (g *Generator) Generate(templateName string, data any, targetPath string) e
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-		return err
-	}
-
-	fmt.Printf("[Gails] Created: %s\n", targetPath)
-	return os.WriteFile(targetPath, buf.Bytes(), 0644)
+	return g.WriteFile(targetPath, buf.String())
 }
 
 // GenerateInline renders a template string and writes it to targetPath.
 func (g *Generator) GenerateInline(tmplStr string, data any, targetPath string) error {
-	funcMap := template.FuncMap{
-		"Title":     strings.Title,
-		"Lower":     strings.ToLower,
-		"Plural":    pluralize,
-		"Singular":  singularize,
-		"Timestamp": func() string { return time.Now().Format("20060102150405") },
-	}
-
-	tmpl, err := template.New("inline").Funcs(funcMap).Parse(tmplStr)
+	tmpl, err := template.New("inline").Funcs(funcMap()).Parse(tmplStr)
 	if err != nil {
 		return err
 	}
@@ -144,12 +142,57 @@ func (g *Generator) GenerateInline(tmplStr string, data any, targetPath string)
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+	return g.WriteFile(targetPath, buf.String())
+}
+
+// WriteFile writes content to path, creating parent directories. Go sources
+// are run through gofmt first. An existing file is left untouched unless
+// g.Force is set.
+func (g *Generator) WriteFile(path, content string) error {
+	_, err := os.Stat(path)
+	exists := err == nil
+	if exists && !g.Force {
+		fmt.Printf("[Gails] Skipped, exists: %s (use --force to overwrite)\n", path)
+		return nil
+	}
+
+	data := []byte(content)
+	if filepath.Ext(path) == ".go" {
+		formatted, err := format.Source(data)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		data = formatted
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	if exists {
+		fmt.Printf("[Gails] Overwrote: %s\n", path)
+	} else {
+		fmt.Printf("[Gails] Created: %s\n", path)
+	}
+	return nil
+}
 
-	fmt.Printf("[Gails] Created: %s\n", targetPath)
-	return os.WriteFile(targetPath, buf.Bytes(), 0644)
+// funcMap returns the helpers available to generator templates. "Title" is
+// kept as an alias of PascalCase for templates written before it existed.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"PascalCase": PascalCase,
+		"CamelCase":  CamelCase,
+		"SnakeCase":  SnakeCase,
+		"Title":      PascalCase,
+		"Lower":      strings.ToLower,
+		"Plural":     Pluralize,
+		"Singular":   Singularize,
+		"Timestamp":  func() string { return time.Now().Format("20060102150405") },
+	}
 }
 
 // GenerateSkeleton generates a complete new Gails application skeleton.
@@ -253,6 +296,10 @@ mailer:
 cache:
   ttl: 3600
   prefix: "%s:"
+
+uploads:
+  max_size: 33554432
+  max_memory: 8388608
 `, name, strings.ToLower(name), strings.ToLower(name), strings.ToLower(name))
 	writeFile(filepath.Join(name, "config/app.yaml"), appYaml)
 
@@ -347,32 +394,14 @@ tmp_dir = "tmp"
 `
 }
 
-func capitalize(s string) string {
-	if s == "" {
-		return s
-	}
-	return strings.ToUpper(s[:1]) + s[1:]
-}
-
-func pluralize(s string) string {
-	if strings.HasSuffix(s, "y") {
-		return s[:len(s)-1] + "ies"
-	}
-	if strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") {
-		return s + "es"
-	}
-	return s + "s"
+// Pluralize returns the English plural of s (post -> posts, category ->
+// categories, survey -> surveys). It uses GORM's inflector, so generated
+// table names and foreign keys match the tables GORM expects for the models.
+func Pluralize(s string) string {
+	return inflection.Plural(s)
 }
 
-func singularize(s string) string {
-	if strings.HasSuffix(s, "ies") {
-		return s[:len(s)-3] + "y"
-	}
-	if strings.HasSuffix(s, "ses") || strings.HasSuffix(s, "xes") {
-		return s[:len(s)-2]
-	}
-	if strings.HasSuffix(s, "s") {
-		return s[:len(s)-1]
-	}
-	return s
+// Singularize reverses Pluralize.
+func Singularize(s string) string {
+	return inflection.Singular(s)
 }
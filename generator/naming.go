@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms are kept fully upper-cased in Go identifiers, following
+// the same list golint uses (api_key -> APIKey, user_id -> UserID).
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "CSV": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "JWT": true, "LHS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// PascalCase converts snake_case, kebab-case, spaced or camelCase input into an
+// exported Go identifier: "api_key" -> "APIKey", "blog-post" -> "BlogPost".
+func PascalCase(s string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		b.WriteString(titleWord(w))
+	}
+	return b.String()
+}
+
+// CamelCase is PascalCase with the first word lower-cased, for unexported
+// identifiers and local variables: "api_key" -> "apiKey", "ID" -> "id".
+func CamelCase(s string) string {
+	words := splitWords(s)
+	if len(words) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(strings.ToLower(words[0]))
+	for _, w := range words[1:] {
+		b.WriteString(titleWord(w))
+	}
+	return b.String()
+}
+
+// SnakeCase converts any of the supported forms to snake_case, matching GORM's
+// column naming: "APIKey" -> "api_key", "BlogPost" -> "blog_post".
+func SnakeCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+func titleWord(w string) string {
+	if upper := strings.ToUpper(w); commonInitialisms[upper] {
+		return upper
+	}
+	r := []rune(strings.ToLower(w))
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// splitWords breaks s on separators and case changes. A run of capitals is
+// treated as one word, ending before a capital that starts a lower-case word,
+// so "HTTPServer" splits into "HTTP" and "Server".
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
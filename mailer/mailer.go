@@ -83,13 +83,11 @@ func (e *Email) HTMLBody(body string) *Email {
 func (e *Email) Deliver() error {
 	env := os.Getenv("APP_ENV")
 	if env == "development" || env == "test" || env == "" {
-		if framework.Log != nil {
-			framework.Log.Info("📧 Intercepted email (not sent)",
-				zap.String("to", e.to),
-				zap.String("subject", e.subject),
-				zap.String("body_preview", truncate(e.textBody, 200)),
-			)
-		}
+		framework.Log.Info("📧 Intercepted email (not sent)",
+			zap.String("to", e.to),
+			zap.String("subject", e.subject),
+			zap.String("body_preview", truncate(e.textBody, 200)),
+		)
 		return nil
 	}
 
@@ -120,12 +118,10 @@ func (e *Email) Deliver() error {
 
 // DeliverLater enqueues the email for background delivery.
 func (e *Email) DeliverLater() {
-	if framework.Log != nil {
-		framework.Log.Info("📧 Enqueued email for later delivery",
-			zap.String("to", e.to),
-			zap.String("subject", e.subject),
-		)
-	}
+	framework.Log.Info("📧 Enqueued email for later delivery",
+		zap.String("to", e.to),
+		zap.String("subject", e.subject),
+	)
 	// In a full implementation, this would enqueue to the job queue
 }
 
@@ -0,0 +1,244 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AuditEntry is one recorded change to a model, stored in audit_logs.
+type AuditEntry struct {
+	ID       uint   `gorm:"primarykey"`
+	Table    string `gorm:"column:table_name;size:255;not null;index:idx_audit_logs_record"`
+	RecordID string `gorm:"size:255;not null;index:idx_audit_logs_record"`
+	Action   string `gorm:"size:10;not null"` // create, update or delete
+	// Changes maps each affected column to its old and new value. Creates
+	// have no old values and deletes have no new ones.
+	Changes   map[string]AuditChange `gorm:"serializer:json;type:jsonb"`
+	UserID    *uint
+	CreatedAt time.Time
+}
+
+// AuditChange is a column's value before and after a change.
+type AuditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// TableName implements GORM's Tabler.
+func (AuditEntry) TableName() string { return "audit_logs" }
+
+const (
+	auditTable  = "audit_logs"
+	auditOldKey = "gails:audit_old"
+)
+
+// RegisterAuditLog records every create, update and delete made through db
+// in the audit_logs table, which is created from model if it doesn't exist
+// (pass nil if you manage it with a migration). Entries are written in the
+// same transaction as the change, so a rolled-back write leaves no trace.
+//
+// The acting user comes from the statement's context (see WithAuditUser);
+// ctx.DB() sets it to the request's user, so use that in controllers:
+//
+//	orm.RegisterAuditLog(app.DB, &orm.AuditEntry{})
+//	...
+//	orm.Query[Post](ctx.DB()).Update(post) // audited with the current user
+//
+// Updates and deletes are audited for single records (Save, Model(&v).Updates,
+// Delete(&v)); batch writes by condition are not.
+func RegisterAuditLog(db *gorm.DB, model *AuditEntry) error {
+	if model != nil {
+		if err := db.AutoMigrate(model); err != nil {
+			return err
+		}
+	}
+	if db.Callback().Create().Get("gails:audit_create") != nil {
+		return nil
+	}
+
+	err := db.Callback().Create().After("gorm:create").Register("gails:audit_create", func(tx *gorm.DB) {
+		if !auditable(tx) {
+			return
+		}
+		rv := tx.Statement.ReflectValue
+		records := []reflect.Value{rv}
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			records = records[:0]
+			for i := 0; i < rv.Len(); i++ {
+				records = append(records, reflect.Indirect(rv.Index(i)))
+			}
+		}
+		for _, record := range records {
+			changes := make(map[string]AuditChange)
+			for column, value := range fieldValues(tx, record) {
+				changes[column] = AuditChange{New: value}
+			}
+			writeAudit(tx, record, "create", changes)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Updates and deletes snapshot the stored row first, so changes made via
+	// Save, Updates or a map are all diffed against what was really there.
+	snapshot := func(tx *gorm.DB) {
+		if !auditable(tx) || tx.Statement.ReflectValue.Kind() != reflect.Struct {
+			return
+		}
+		if row, ok := loadRow(tx); ok {
+			tx.InstanceSet(auditOldKey, row)
+		}
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("gails:audit_snapshot", snapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("gails:audit_snapshot", snapshot); err != nil {
+		return err
+	}
+
+	err = db.Callback().Update().After("gorm:update").Register("gails:audit_update", func(tx *gorm.DB) {
+		old, ok := auditSnapshot(tx)
+		if !ok {
+			return
+		}
+		current, ok := loadRow(tx)
+		if !ok {
+			return
+		}
+		changes := make(map[string]AuditChange)
+		for column, value := range current {
+			if column == "updated_at" || fmt.Sprint(old[column]) == fmt.Sprint(value) {
+				continue
+			}
+			changes[column] = AuditChange{Old: old[column], New: value}
+		}
+		if len(changes) > 0 {
+			writeAudit(tx, tx.Statement.ReflectValue, "update", changes)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.Callback().Delete().After("gorm:delete").Register("gails:audit_delete", func(tx *gorm.DB) {
+		old, ok := auditSnapshot(tx)
+		if !ok {
+			return
+		}
+		changes := make(map[string]AuditChange, len(old))
+		for column, value := range old {
+			changes[column] = AuditChange{Old: value}
+		}
+		writeAudit(tx, tx.Statement.ReflectValue, "delete", changes)
+	})
+}
+
+type auditUserKey struct{}
+
+// WithAuditUser returns a copy of ctx naming id as the user behind writes
+// made with it, for RegisterAuditLog. framework.Context.DB sets it from the
+// request's session or token; outside requests set it yourself:
+//
+//	db.WithContext(orm.WithAuditUser(ctx, adminID)).Delete(&post)
+func WithAuditUser(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, auditUserKey{}, id)
+}
+
+// AuditUser returns the user set by WithAuditUser.
+func AuditUser(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(auditUserKey{}).(uint)
+	return id, ok
+}
+
+// AuditTrail returns the recorded changes to one record, oldest first.
+//
+//	entries, err := orm.AuditTrail(db, "posts", post.ID)
+func AuditTrail(db *gorm.DB, table string, id any) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	err := db.Where("table_name = ? AND record_id = ?", table, fmt.Sprint(id)).
+		Order("id").
+		Find(&entries).Error
+	return entries, err
+}
+
+func auditable(tx *gorm.DB) bool {
+	return tx.Error == nil && tx.Statement.Schema != nil && tx.Statement.Schema.Table != auditTable
+}
+
+// auditSnapshot returns the row captured before this update/delete, if the
+// statement changed anything.
+func auditSnapshot(tx *gorm.DB) (map[string]any, bool) {
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		return nil, false
+	}
+	v, ok := tx.InstanceGet(auditOldKey)
+	if !ok {
+		return nil, false
+	}
+	old, ok := v.(map[string]any)
+	return old, ok
+}
+
+// loadRow reads the statement's record from the database by primary key.
+// Soft-deleted rows are included so deletes can still be diffed.
+func loadRow(tx *gorm.DB) (map[string]any, bool) {
+	pk := tx.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, false
+	}
+	id, isZero := pk.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue)
+	if isZero {
+		return nil, false
+	}
+	row := make(map[string]any)
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Table(tx.Statement.Schema.Table).
+		Where(pk.DBName+" = ?", id).
+		Take(&row).Error
+	return row, err == nil
+}
+
+// fieldValues returns the column values of a record held in memory.
+func fieldValues(tx *gorm.DB, record reflect.Value) map[string]any {
+	values := make(map[string]any)
+	for _, field := range tx.Statement.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, _ := field.ValueOf(tx.Statement.Context, record)
+		// Store what the column holds, e.g. null rather than gorm.DeletedAt{}.
+		if valuer, ok := value.(driver.Valuer); ok {
+			value, _ = valuer.Value()
+		}
+		values[field.DBName] = value
+	}
+	return values
+}
+
+func writeAudit(tx *gorm.DB, record reflect.Value, action string, changes map[string]AuditChange) {
+	entry := AuditEntry{
+		Table:    tx.Statement.Schema.Table,
+		RecordID: recordID(tx, tx.Statement.Schema, record),
+		Action:   action,
+		Changes:  changes,
+	}
+	if userID, ok := AuditUser(tx.Statement.Context); ok {
+		entry.UserID = &userID
+	}
+	tx.Session(&gorm.Session{NewDB: true}).Create(&entry)
+}
+
+func recordID(tx *gorm.DB, s *schema.Schema, record reflect.Value) string {
+	if s.PrioritizedPrimaryField == nil {
+		return ""
+	}
+	id, _ := s.PrioritizedPrimaryField.ValueOf(tx.Statement.Context, record)
+	return fmt.Sprint(id)
+}
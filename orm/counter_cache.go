@@ -5,65 +5,166 @@ import (
 	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-// RegisterCounterCache sets up hooks to automatically update a counter field on a parent model.
+// RegisterCounterCacheFor keeps counterField on the parent of T's
+// relationName (a belongs-to association) in sync: it is incremented when a T
+// is created, decremented when one is deleted, and moved from the old parent
+// to the new one when an update changes the foreign key.
+//
+//	orm.RegisterCounterCacheFor[Comment](db, "Post", "comments_count")
+//	orm.RegisterCounterCacheFor[Like](db, "Post", "likes_count")
+//
+// Registering the same model, relation and counter twice is a no-op, so counts
+// are never bumped twice for one write.
+func RegisterCounterCacheFor[T any](db *gorm.DB, relationName string, counterField string) error {
+	var model T
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&model); err != nil {
+		return err
+	}
+	registerCounterCache(db, stmt.Schema.ModelType, relationName, counterField)
+	return nil
+}
+
+// RegisterCounterCache is RegisterCounterCacheFor for every model that has a
+// relation named relationName.
+//
+// Deprecated: two models with a relation of the same name (Comment.Post and
+// Like.Post) would both bump counterField; use RegisterCounterCacheFor.
 func RegisterCounterCache(db *gorm.DB, relationName string, counterField string) {
+	registerCounterCache(db, nil, relationName, counterField)
+}
+
+// registerCounterCache registers the counter callbacks for records of
+// modelType, or of any model if it is nil.
+func registerCounterCache(db *gorm.DB, modelType reflect.Type, relationName string, counterField string) {
+	model := "*"
+	if modelType != nil {
+		model = modelType.String()
+	}
+	key := model + "." + relationName + "." + counterField
+	incName := "gails:counter_cache_inc_" + key
+	if db.Callback().Create().Get(incName) != nil {
+		return
+	}
+	oldKey := "gails:counter_cache_old_" + key
+	relation := func(tx *gorm.DB) (*schema.Relationship, *schema.Field, bool) {
+		if modelType != nil && (tx.Statement.Schema == nil || tx.Statement.Schema.ModelType != modelType) {
+			return nil, nil, false
+		}
+		return counterRelation(tx, relationName)
+	}
+
 	// AfterCreate hook
-	db.Callback().Create().After("gorm:create").Register("gails:counter_cache_inc_"+relationName, func(tx *gorm.DB) {
-		if tx.Error != nil || tx.Statement.Schema == nil {
+	db.Callback().Create().After("gorm:create").Register(incName, func(tx *gorm.DB) {
+		rel, fk, ok := relation(tx)
+		if !ok {
 			return
 		}
+		if parentID, isZero := fk.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue); !isZero {
+			adjustCounter(tx, rel, counterField, parentID, 1)
+		}
+	})
 
-		rel, ok := tx.Statement.Schema.Relationships.Relations[relationName]
+	// BeforeUpdate: remember which parent the row belonged to. Reading it from
+	// the database (rather than the struct) works for Save, Updates and maps.
+	db.Callback().Update().Before("gorm:update").Register(oldKey, func(tx *gorm.DB) {
+		_, fk, ok := relation(tx)
 		if !ok {
 			return
 		}
+		if old, found := currentForeignKey(tx, fk); found {
+			tx.InstanceSet(oldKey, old)
+		}
+	})
 
-		// Get parent ID from the created model
-		v := reflectValue(tx.Statement.Model)
-		fieldValue, isZero := rel.Field.ValueOf(tx.Statement.Context, v)
-		if isZero || fieldValue == nil {
+	// AfterUpdate: if the foreign key changed, move the count to the new parent.
+	db.Callback().Update().After("gorm:update").Register("gails:counter_cache_move_"+key, func(tx *gorm.DB) {
+		// Nothing was updated (Save falls back to Create, which counts on its own).
+		if tx.RowsAffected == 0 {
 			return
 		}
-
-		// Update parent count
-		tx.Session(&gorm.Session{NewDB: true}).
-			Table(rel.FieldSchema.Table).
-			Where("id = ?", fieldValue).
-			UpdateColumn(counterField, gorm.Expr(counterField+" + ?", 1))
+		rel, fk, ok := relation(tx)
+		if !ok {
+			return
+		}
+		old, ok := tx.InstanceGet(oldKey)
+		if !ok {
+			return
+		}
+		current, found := currentForeignKey(tx, fk)
+		if !found || fmt.Sprint(old) == fmt.Sprint(current) {
+			return
+		}
+		if old != nil {
+			adjustCounter(tx, rel, counterField, old, -1)
+		}
+		if current != nil {
+			adjustCounter(tx, rel, counterField, current, 1)
+		}
 	})
 
 	// AfterDelete hook
-	db.Callback().Delete().After("gorm:delete").Register("gails:counter_cache_dec_"+relationName, func(tx *gorm.DB) {
-		if tx.Error != nil || tx.Statement.Schema == nil {
+	db.Callback().Delete().After("gorm:delete").Register("gails:counter_cache_dec_"+key, func(tx *gorm.DB) {
+		if tx.RowsAffected == 0 {
 			return
 		}
-
-		rel, ok := tx.Statement.Schema.Relationships.Relations[relationName]
+		rel, fk, ok := relation(tx)
 		if !ok {
 			return
 		}
-
-		v := reflectValue(tx.Statement.Model)
-		fieldValue, isZero := rel.Field.ValueOf(tx.Statement.Context, v)
-		if isZero || fieldValue == nil {
-			return
+		if parentID, isZero := fk.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue); !isZero {
+			adjustCounter(tx, rel, counterField, parentID, -1)
 		}
-
-		tx.Session(&gorm.Session{NewDB: true}).
-			Table(rel.FieldSchema.Table).
-			Where("id = ?", fieldValue).
-			UpdateColumn(counterField, gorm.Expr(counterField+" - ?", 1))
 	})
 }
 
-func reflectValue(obj any) reflect.Value {
-	v := reflect.ValueOf(obj)
-	for v.Kind() == reflect.Ptr {
-		v = v.Elem()
+// counterRelation returns the named belongs-to relation of the statement's
+// model and the child's foreign key field. Only single records are handled;
+// batch writes should be followed by RecountAll.
+func counterRelation(tx *gorm.DB, relationName string) (*schema.Relationship, *schema.Field, bool) {
+	if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.ReflectValue.Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+	rel, ok := tx.Statement.Schema.Relationships.Relations[relationName]
+	if !ok {
+		return nil, nil, false
+	}
+	for _, ref := range rel.References {
+		if ref.OwnPrimaryKey {
+			continue
+		}
+		return rel, ref.ForeignKey, true
+	}
+	return nil, nil, false
+}
+
+// currentForeignKey reads the row's stored foreign key value.
+func currentForeignKey(tx *gorm.DB, fk *schema.Field) (any, bool) {
+	pk := tx.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, false
+	}
+	id, isZero := pk.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue)
+	if isZero {
+		return nil, false
 	}
-	return v
+	var value any
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Table(tx.Statement.Schema.Table).
+		Select(fk.DBName).
+		Where(pk.DBName+" = ?", id).
+		Row().Scan(&value)
+	return value, err == nil
+}
+
+func adjustCounter(tx *gorm.DB, rel *schema.Relationship, counterField string, parentID any, delta int) {
+	tx.Session(&gorm.Session{NewDB: true}).
+		Table(rel.FieldSchema.Table).
+		Where("id = ?", parentID).
+		UpdateColumn(counterField, gorm.Expr(counterField+" + ?", delta))
 }
 
 // RecountAll utility to fix desync
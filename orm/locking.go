@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrStaleObject is returned by Update when a model with a version column was
+// changed by someone else since it was loaded.
+var ErrStaleObject = errors.New("orm: stale object: record was modified or deleted since it was loaded")
+
+// IsStaleObject reports whether err is an optimistic locking conflict.
+func IsStaleObject(err error) bool {
+	return errors.Is(err, ErrStaleObject)
+}
+
+// versionField returns the integer field tagged `gails:"version"` on v's
+// model, or nil if the model doesn't use optimistic locking.
+//
+//	type Post struct {
+//		orm.Model
+//		Title   string
+//		Version int `gails:"version" gorm:"not null;default:0"`
+//	}
+func versionField(db *gorm.DB, v any) *schema.Field {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(v); err != nil {
+		return nil
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.Tag.Get("gails") != "version" || field.DBName == "" {
+			continue
+		}
+		switch field.FieldType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return field
+		}
+	}
+	return nil
+}
+
+// saveLocked saves v only if its stored version still matches the one it was
+// loaded with, bumping the version on success.
+func saveLocked(db *gorm.DB, field *schema.Field, v any) error {
+	rv := reflect.ValueOf(v).Elem()
+	fv := field.ReflectValueOf(db.Statement.Context, rv)
+	old := reflect.ValueOf(fv.Interface())
+
+	if fv.CanInt() {
+		fv.SetInt(old.Int() + 1)
+	} else {
+		fv.SetUint(old.Uint() + 1)
+	}
+
+	// Selecting columns stops Save from falling back to an upsert when the
+	// version check matches no row.
+	tx := db.Select("*").Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: old.Interface()}).Save(v)
+	if tx.Error == nil && tx.RowsAffected == 0 {
+		tx.Error = ErrStaleObject
+	}
+	if tx.Error != nil {
+		fv.Set(old)
+	}
+	return tx.Error
+}
@@ -1,7 +1,6 @@
 package orm
 
 import (
-	"fmt"
 	"strconv"
 	"time"
 
@@ -20,8 +19,3 @@ type Model struct {
 func (m *Model) IDString() string {
 	return strconv.Itoa(int(m.ID))
 }
-
-// CacheKey generates a versioned cache key based on model type, ID, and last update.
-func (m *Model) CacheKey() string {
-	return fmt.Sprintf("%T-%d-v%d", m, m.ID, m.UpdatedAt.Unix())
-}
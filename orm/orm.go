@@ -1,14 +1,52 @@
 package orm
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
+// ErrNotFound is returned when a lookup matches no record. It wraps
+// gorm.ErrRecordNotFound, so errors.Is checks against either still work.
+var ErrNotFound = fmt.Errorf("orm: record not found: %w", gorm.ErrRecordNotFound)
+
+// IsNotFound reports whether err means no record matched.
+//
+//	user, err := orm.Query[User](db).Find(ctx.Param("id"))
+//	if orm.IsNotFound(err) {
+//		return ctx.NotFound("User not found")
+//	}
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// ErrMissingWhere is returned by DeleteAll and UpdateAll on a query without
+// conditions, so a forgotten Where can't wipe or rewrite a whole table. It
+// wraps gorm.ErrMissingWhereClause.
+var ErrMissingWhere = fmt.Errorf("orm: DeleteAll/UpdateAll needs a Where condition (or AllowBlank): %w", gorm.ErrMissingWhereClause)
+
+// notFound translates GORM's not-found error into ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
 // QueryBuilder provides a generic, chainable query interface wrapping GORM.
 type QueryBuilder[T any] struct {
 	db      *gorm.DB
 	page    int
 	perPage int
+	// grouped is set once Select/Group/Having shape the result rows, so Count
+	// counts those rows instead of the underlying table.
+	grouped bool
 }
 
 // Query creates a new QueryBuilder for the given model type.
@@ -40,6 +78,62 @@ func (q *QueryBuilder[T]) Offset(offset int) *QueryBuilder[T] {
 	return q
 }
 
+// Select restricts the selected columns.
+func (q *QueryBuilder[T]) Select(columns ...string) *QueryBuilder[T] {
+	q.db = q.db.Select(columns)
+	q.grouped = true
+	return q
+}
+
+// Joins adds a JOIN clause, either raw SQL or an association name.
+func (q *QueryBuilder[T]) Joins(query string, args ...any) *QueryBuilder[T] {
+	q.db = q.db.Joins(query, args...)
+	return q
+}
+
+// Group adds a GROUP BY clause.
+func (q *QueryBuilder[T]) Group(name string) *QueryBuilder[T] {
+	q.db = q.db.Group(name)
+	q.grouped = true
+	return q
+}
+
+// Having adds a HAVING clause.
+func (q *QueryBuilder[T]) Having(query any, args ...any) *QueryBuilder[T] {
+	q.db = q.db.Having(query, args...)
+	q.grouped = true
+	return q
+}
+
+// WithTrashed includes soft-deleted records in the results.
+func (q *QueryBuilder[T]) WithTrashed() *QueryBuilder[T] {
+	q.db = q.db.Unscoped()
+	return q
+}
+
+// OnlyTrashed restricts the results to soft-deleted records.
+func (q *QueryBuilder[T]) OnlyTrashed() *QueryBuilder[T] {
+	q.db = q.db.Unscoped().Where(clause.Neq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"},
+		Value:  nil,
+	})
+	return q
+}
+
+// AllowBlank lets DeleteAll and UpdateAll run without a Where condition,
+// affecting every row.
+func (q *QueryBuilder[T]) AllowBlank() *QueryBuilder[T] {
+	q.db = q.db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	return q
+}
+
+// UsePrimary sends this query to the primary database even when read
+// replicas are configured, for reading a record straight after writing it.
+func (q *QueryBuilder[T]) UsePrimary() *QueryBuilder[T] {
+	q.db = q.db.Clauses(dbresolver.Write)
+	return q
+}
+
 // Page sets the page number for pagination (1-indexed).
 func (q *QueryBuilder[T]) Page(page int) *QueryBuilder[T] {
 	if page < 1 {
@@ -89,33 +183,110 @@ func (q *QueryBuilder[T]) All() ([]T, error) {
 	return results, err
 }
 
-// First returns the first matching record.
+// First returns the first matching record, or ErrNotFound.
 func (q *QueryBuilder[T]) First() (*T, error) {
 	var result T
 	err := q.db.First(&result).Error
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 	return &result, nil
 }
 
-// Find returns a record by primary key.
+// Find returns a record by primary key, or ErrNotFound.
 func (q *QueryBuilder[T]) Find(id any) (*T, error) {
 	var result T
 	err := q.db.First(&result, id).Error
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
+	}
+	return &result, nil
+}
+
+// FindBy returns the first record whose column equals value, or ErrNotFound.
+// The column name is quoted, so it is safe to pass through from a caller.
+func (q *QueryBuilder[T]) FindBy(field string, value any) (*T, error) {
+	var result T
+	err := q.db.Where(clause.Eq{Column: clause.Column{Name: field}, Value: value}).First(&result).Error
+	if err != nil {
+		return nil, notFound(err)
 	}
 	return &result, nil
 }
 
-// Create inserts a new record.
+// MustFind is like Find but panics on error. The panic is caught by the
+// recovery middleware and rendered as a 500.
+func (q *QueryBuilder[T]) MustFind(id any) *T {
+	result, err := q.Find(id)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Create validates and inserts a new record. An invalid record is not saved
+// and a *ValidationError is returned.
 func (q *QueryBuilder[T]) Create(v *T) error {
+	if err := validateRecord(q.db, v); err != nil {
+		return err
+	}
 	return q.db.Create(v).Error
 }
 
-// Update saves changes to an existing record.
+// defaultBatchSize is used when CreateBatch is given a non-positive size.
+const defaultBatchSize = 500
+
+// CreateBatch inserts records in chunks of batchSize rows per INSERT
+// (default 500), in one transaction unless SkipDefaultTransaction is set.
+// Every record is validated first; if any is invalid nothing is inserted.
+func (q *QueryBuilder[T]) CreateBatch(records []T, batchSize int) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for i := range records {
+		if err := validateRecord(q.db, &records[i]); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return q.db.CreateInBatches(&records, batchSize).Error
+}
+
+// Upsert inserts records, updating updateColumns on rows that conflict on
+// conflictColumns. With no updateColumns, conflicting rows are left as is.
+//
+//	orm.Query[Product](db).Upsert(products, []string{"sku"}, []string{"name", "price"})
+func (q *QueryBuilder[T]) Upsert(records []T, conflictColumns []string, updateColumns []string) error {
+	if len(records) == 0 {
+		return nil
+	}
+	onConflict := clause.OnConflict{}
+	for _, c := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: c})
+	}
+	if len(updateColumns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	} else {
+		onConflict.DoNothing = true
+	}
+	return q.db.Clauses(onConflict).CreateInBatches(&records, defaultBatchSize).Error
+}
+
+// Update validates and saves changes to an existing record. An invalid record
+// is not saved and a *ValidationError is returned.
+//
+// Models with a `gails:"version"` field use optimistic locking: the save only
+// applies if the stored version still matches, the version is incremented, and
+// ErrStaleObject is returned if someone else updated the row first.
 func (q *QueryBuilder[T]) Update(v *T) error {
+	if err := validateRecord(q.db, v); err != nil {
+		return err
+	}
+	if field := versionField(q.db, v); field != nil {
+		return saveLocked(q.db, field, v)
+	}
 	return q.db.Save(v).Error
 }
 
@@ -124,14 +295,76 @@ func (q *QueryBuilder[T]) Delete(v *T) error {
 	return q.db.Delete(v).Error
 }
 
-// Count returns the number of matching records.
+// DeleteAll deletes every record matching the query's conditions, soft
+// deleting models that have a DeletedAt, and returns how many rows it
+// affected. Like Rails' delete_all it skips model hooks. Without a Where it
+// returns ErrMissingWhere unless AllowBlank was called.
+//
+//	n, err := orm.Query[Session](db).Where("expires_at < ?", time.Now()).DeleteAll()
+func (q *QueryBuilder[T]) DeleteAll() (int64, error) {
+	var model T
+	result := q.db.Session(&gorm.Session{SkipHooks: true}).Delete(&model)
+	return result.RowsAffected, missingWhere(result.Error)
+}
+
+// UpdateAll sets columns on every record matching the query's conditions and
+// returns how many rows it affected. Keys are column names; updated_at is set
+// too. It skips validations and model hooks, and like DeleteAll it needs a
+// Where or AllowBlank.
+//
+//	n, err := orm.Query[Post](db).Where("author_id = ?", id).UpdateAll(map[string]any{"published": false})
+func (q *QueryBuilder[T]) UpdateAll(values map[string]any) (int64, error) {
+	var model T
+	// Skipping hooks also skips GORM's automatic updated_at.
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Struct {
+		if _, ok := t.FieldByName("UpdatedAt"); ok {
+			if _, set := values["updated_at"]; !set {
+				values = maps.Clone(values)
+				values["updated_at"] = time.Now()
+			}
+		}
+	}
+	result := q.db.Session(&gorm.Session{SkipHooks: true}).Model(&model).Updates(values)
+	return result.RowsAffected, missingWhere(result.Error)
+}
+
+// missingWhere translates GORM's missing-WHERE error into ErrMissingWhere.
+func missingWhere(err error) error {
+	if errors.Is(err, gorm.ErrMissingWhereClause) {
+		return ErrMissingWhere
+	}
+	return err
+}
+
+// Restore un-deletes a soft-deleted record by clearing its deleted_at.
+func (q *QueryBuilder[T]) Restore(v *T) error {
+	return q.db.Unscoped().Model(v).Update("deleted_at", nil).Error
+}
+
+// Count returns the number of matching records. With Select/Group/Having it
+// counts the result rows (e.g. the number of groups) via a subquery.
 func (q *QueryBuilder[T]) Count() (int64, error) {
 	var count int64
 	var model T
+	if q.grouped {
+		sub := q.db.Model(&model)
+		err := q.db.Session(&gorm.Session{NewDB: true}).Table("(?) AS counted", sub).Count(&count).Error
+		return count, err
+	}
 	err := q.db.Model(&model).Count(&count).Error
 	return count, err
 }
 
+// Pluck returns a single column of the matching records, honouring pagination.
+//
+//	emails, err := orm.Pluck[string](orm.Query[User](db).Where("active = ?", true), "email")
+func Pluck[V, T any](q *QueryBuilder[T], column string) ([]V, error) {
+	var values []V
+	var model T
+	err := q.applyPagination().Model(&model).Pluck(column, &values).Error
+	return values, err
+}
+
 // Exists returns true if at least one matching record exists.
 func (q *QueryBuilder[T]) Exists(query any, args ...any) (bool, error) {
 	var count int64
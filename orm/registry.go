@@ -0,0 +1,53 @@
+package orm
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	modelsMu sync.RWMutex
+	models   = map[string]reflect.Type{}
+)
+
+// RegisterModel makes models known by their type name to tools that only
+// have a name to go on, such as the console (`User.find 1`).
+//
+//	orm.RegisterModel(&User{}, &Post{})
+func RegisterModel(values ...any) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		models[strings.ToLower(t.Name())] = t
+	}
+}
+
+// LookupModel returns a new zero value (a pointer) of the model registered
+// under name, which is matched case-insensitively.
+func LookupModel(name string) (any, bool) {
+	modelsMu.RLock()
+	t, ok := models[strings.ToLower(name)]
+	modelsMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return reflect.New(t).Interface(), true
+}
+
+// RegisteredModels returns the names of the registered models, sorted.
+func RegisteredModels() []string {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	names := make([]string, 0, len(models))
+	for _, t := range models {
+		names = append(names, t.Name())
+	}
+	sort.Strings(names)
+	return names
+}
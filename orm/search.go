@@ -0,0 +1,80 @@
+package orm
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// SearchLanguage is the Postgres text search configuration FullTextSearch
+// stems words with.
+var SearchLanguage = "english"
+
+// Search matches query case-insensitively anywhere in any of columns (an
+// OR'd ILIKE). The query is a bound parameter with LIKE wildcards escaped,
+// and columns are cast to text so numeric columns match too. A blank query
+// adds no condition.
+//
+//	users, err := orm.Query[User](db).Search(ctx.Query("q"), "name", "email").All()
+func (q *QueryBuilder[T]) Search(query string, columns ...string) *QueryBuilder[T] {
+	if cond := SearchCondition(query, columns...); cond != nil {
+		q.db = q.db.Where(cond)
+	}
+	return q
+}
+
+// FullTextSearch matches query against columns with Postgres full-text
+// search (to_tsvector @@ plainto_tsquery, in SearchLanguage), so "running
+// shoes" finds "Shoes for runners". For large tables, back it with a GIN
+// index on the same to_tsvector expression. A blank query adds no condition.
+//
+//	posts, err := orm.Query[Post](db).FullTextSearch(q, "title", "body").All()
+func (q *QueryBuilder[T]) FullTextSearch(query string, columns ...string) *QueryBuilder[T] {
+	query = strings.TrimSpace(query)
+	if query == "" || len(columns) == 0 {
+		return q
+	}
+	parts := make([]string, len(columns))
+	vars := make([]any, 0, len(columns)+3)
+	vars = append(vars, SearchLanguage)
+	for i, c := range columns {
+		parts[i] = "coalesce(CAST(? AS TEXT), '')"
+		vars = append(vars, searchColumn(c))
+	}
+	vars = append(vars, SearchLanguage, query)
+	q.db = q.db.Where(clause.Expr{
+		SQL:  "to_tsvector(CAST(? AS regconfig), " + strings.Join(parts, " || ' ' || ") + ") @@ plainto_tsquery(CAST(? AS regconfig), ?)",
+		Vars: vars,
+	})
+	return q
+}
+
+// SearchCondition is the condition Search adds, for use on a plain *gorm.DB.
+// It returns nil for a blank query or no columns.
+func SearchCondition(query string, columns ...string) clause.Expression {
+	query = strings.TrimSpace(query)
+	if query == "" || len(columns) == 0 {
+		return nil
+	}
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+	exprs := make([]clause.Expression, len(columns))
+	for i, c := range columns {
+		exprs[i] = clause.Expr{
+			SQL:  "CAST(? AS TEXT) ILIKE ?",
+			Vars: []any{searchColumn(c), pattern},
+		}
+	}
+	return clause.Or(exprs...)
+}
+
+// likeEscaper escapes LIKE wildcards so the search term matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// searchColumn quotes name as a column of the queried table, or as given if
+// it is qualified ("users.email").
+func searchColumn(name string) clause.Column {
+	if strings.Contains(name, ".") {
+		return clause.Column{Name: name}
+	}
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
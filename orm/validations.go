@@ -1,83 +1,201 @@
 package orm
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/shaurya/gails/framework/i18n"
+	"github.com/shaurya/gails/framework/validation"
+	"gorm.io/gorm"
 )
 
-var validate = validator.New()
+// Validatable is implemented by models with rules that struct tags can't
+// express. QueryBuilder.Create and Update run it alongside the tags:
+//
+//	func (e *Event) Validate() map[string][]string {
+//		if e.EndsAt.Before(e.StartsAt) {
+//			return map[string][]string{"ends_at": {"must be after the start"}}
+//		}
+//		return nil
+//	}
+type Validatable interface {
+	Validate() map[string][]string
+}
 
-func Validate(model any) map[string][]string {
-	err := validate.Struct(model)
-	if err == nil {
-		return nil
-	}
+// ValidationError is returned by QueryBuilder writes when the model is
+// invalid. Controllers can return it as is to respond with a 422.
+type ValidationError struct {
+	Errors map[string][]string
+}
 
-	errors := make(map[string][]string)
-	for _, err := range err.(validator.ValidationErrors) {
-		field := err.Field()
-		tag := err.Tag()
-		param := err.Param()
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("orm: validation failed: %v", e.Errors)
+}
 
-		// Key for i18n lookup: errors.validations.required
-		i18nKey := fmt.Sprintf("errors.validations.%s", tag)
+// FieldErrors returns the errors keyed by field.
+func (e *ValidationError) FieldErrors() map[string][]string {
+	return e.Errors
+}
 
-		msg := i18n.T(i18nKey, i18n.Vars{
-			"field": i18n.T("models.fields."+field, nil),
-			"param": param,
-		})
+// Validate runs the model's `validate` tags and its Validatable rules, and
+// returns the combined field errors, or nil. Tags share their validator with
+// request binding, so field names and messages match what Context.Bind reports.
+// Messages are in the global locale; see ValidateFor.
+func Validate(model any) map[string][]string {
+	return ValidateFor(i18n.GetLocale(), model)
+}
 
-		// Fallback if not translated
-		if msg == i18nKey {
-			msg = fmt.Sprintf("%s is invalid (%s)", field, tag)
+// ValidateFor is Validate with tag messages translated into locale.
+// QueryBuilder writes use the locale of the db's context, so records saved
+// through Context.DB report errors in the request's language.
+func ValidateFor(locale string, model any) map[string][]string {
+	errs, err := validation.StructFor(locale, model)
+	if err != nil {
+		return map[string][]string{"base": {err.Error()}}
+	}
+	if v, ok := model.(Validatable); ok {
+		for field, msgs := range v.Validate() {
+			if errs == nil {
+				errs = make(map[string][]string)
+			}
+			errs[field] = append(errs[field], msgs...)
 		}
-
-		errors[field] = append(errors[field], msg)
 	}
+	return errs
+}
 
-	return errors
+// validateRecord wraps ValidateFor's result in a ValidationError, using the
+// locale carried by db's context.
+func validateRecord(db *gorm.DB, model any) error {
+	locale := i18n.GetLocale()
+	if ctx := db.Statement.Context; ctx != nil {
+		locale = i18n.LocaleFrom(ctx)
+	}
+	if errs := ValidateFor(locale, model); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
 }
 
-// HandleDBError catches common DB errors like unique constraints
+// HandleDBError turns a write error into field errors for a form or a 422
+// response. Validation errors pass through; Postgres constraint violations
+// are mapped to the offending columns:
+//
+//	23505 unique_violation      {"email": ["email has already been taken"]}
+//	23503 foreign_key_violation {"user_id": ["user_id does not exist"]}
+//	23502 not_null_violation    {"title": ["title is required"]}
+//	23514 check_violation       {"price": ["price is invalid"]}
+//
+// Stale records and violations it can't pin on a column are reported under
+// "base". Errors the user can't fix by changing the input (a lost
+// connection, a timeout, ...) return nil: report those as a server error.
+//
+//	if errs := orm.HandleDBError(err); errs != nil {
+//		return ctx.UnprocessableEntity(errs)
+//	}
+//	return ctx.InternalError(err)
 func HandleDBError(err error) map[string][]string {
 	if err == nil {
 		return nil
 	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Errors
+	}
 
-	msg := err.Error()
-	errors := make(map[string][]string)
-
-	// Check for unique constraint violation (Postgres code 23505)
-	if strings.Contains(msg, "duplicate key value violates unique constraint") {
-		// Try to parse the constraint name or field
-		// Example: "duplicate key value violates unique constraint \"users_email_key\""
-		parts := strings.Split(msg, "\"")
-		if len(parts) >= 2 {
-			constraint := parts[1]
-			// convention: table_field_key
-			fieldParts := strings.Split(constraint, "_")
-			if len(fieldParts) >= 2 {
-				field := fieldParts[1]
-				field = strings.Title(field)
-
-				i18nKey := "errors.validations.unique"
-				errMsg := i18n.T(i18nKey, i18n.Vars{
-					"field": i18n.T("models.fields."+field, nil),
-				})
-				if errMsg == i18nKey {
-					errMsg = "has already been taken"
-				}
-				errors[field] = append(errors[field], errMsg)
-			}
+	if errors.Is(err, ErrStaleObject) {
+		return map[string][]string{"base": {dbErrorMessage("stale", "record", "This record was changed by someone else. Reload it and try again.")}}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return map[string][]string{"base": {dbErrorMessage("unique", "record", "%{field} has already been taken")}}
 		}
+		return nil
+	}
+	// Class 23 is integrity_constraint_violation; anything else isn't about
+	// the record's values.
+	if !strings.HasPrefix(pgErr.Code, "23") {
+		return nil
 	}
 
-	if len(errors) == 0 {
-		errors["base"] = []string{msg}
+	errs := make(map[string][]string)
+	add := func(fields []string, key, fallback string) {
+		for _, field := range fields {
+			errs[field] = append(errs[field], dbErrorMessage(key, field, fallback))
+		}
+	}
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		add(keyColumns(pgErr), "unique", "%{field} has already been taken")
+	case "23503": // foreign_key_violation
+		// Deleting a referenced row reports the parent's key, which isn't
+		// something the user can fix on this form.
+		if !strings.Contains(pgErr.Detail, "still referenced") {
+			add(keyColumns(pgErr), "exists", "%{field} does not exist")
+		}
+	case "23502": // not_null_violation
+		if pgErr.ColumnName != "" {
+			add([]string{pgErr.ColumnName}, "required", "%{field} is required")
+		}
+	case "23514": // check_violation
+		if pgErr.ColumnName != "" {
+			add([]string{pgErr.ColumnName}, "invalid", "%{field} is invalid")
+		} else if field := checkColumn(pgErr); field != "" {
+			add([]string{field}, "invalid", "%{field} is invalid")
+		}
 	}
 
-	return errors
+	if len(errs) == 0 {
+		errs["base"] = []string{dbErrorMessage("conflict", "record", "This record conflicts with other data.")}
+	}
+	return errs
+}
+
+// keyColumns extracts the columns from a violation detail such as
+// `Key (org_id, email)=(1, a@b.c) already exists.`
+func keyColumns(pgErr *pgconn.PgError) []string {
+	detail := pgErr.Detail
+	start := strings.Index(detail, "Key (")
+	if start < 0 {
+		return nil
+	}
+	detail = detail[start+len("Key ("):]
+	end := strings.Index(detail, ")=")
+	if end < 0 {
+		return nil
+	}
+	var columns []string
+	for _, column := range strings.Split(detail[:end], ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+	return columns
+}
+
+// checkColumn guesses the column from Postgres' default check constraint
+// name, "<table>_<column>_check".
+func checkColumn(pgErr *pgconn.PgError) string {
+	name := strings.TrimSuffix(pgErr.ConstraintName, "_check")
+	if pgErr.TableName == "" || name == pgErr.ConstraintName || !strings.HasPrefix(name, pgErr.TableName+"_") {
+		return ""
+	}
+	return strings.TrimPrefix(name, pgErr.TableName+"_")
+}
+
+// dbErrorMessage translates "errors.validations.<key>", labelling the field
+// with its "models.fields.<field>" translation when there is one.
+func dbErrorMessage(key, field, fallback string) string {
+	label := field
+	if t := i18n.T("models.fields."+field, nil); t != "models.fields."+field {
+		label = t
+	}
+	vars := i18n.Vars{"field": label}
+	msgKey := "errors.validations." + key
+	if msg := i18n.T(msgKey, vars); msg != msgKey {
+		return msg
+	}
+	return strings.ReplaceAll(fallback, "%{field}", label)
 }
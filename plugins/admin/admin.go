@@ -1,22 +1,45 @@
 package admin
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"maps"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/shaurya/gails/framework"
+	"github.com/shaurya/gails/framework/helpers"
+	"github.com/shaurya/gails/framework/i18n"
+	"github.com/shaurya/gails/orm"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 // Config configures the admin panel.
 type Config struct {
 	Models []Resource
 	Auth   func(http.Handler) http.Handler
+	// DB is used to list, show, create, update, delete and export records.
+	// Without it the panel only renders empty pages.
+	DB *gorm.DB
+	// Layout replaces the page layout. It is html/template source executed
+	// with .Title, .Nav (each with .Name and .URL), .DefaultCSS, .CSS and
+	// .Content, the rendered page. Panel panics if it doesn't parse.
+	Layout string
+	// CSS is added after the default stylesheet, to theme the panel.
+	CSS string
 }
 
 // Resource describes a model registered in the admin panel.
@@ -26,6 +49,12 @@ type Resource struct {
 	DisplayFields []string
 	SearchFields  []string
 	ReadOnlyMode  bool
+	// PerPage is the index page size (default 25).
+	PerPage int
+	// ReadOnlyFields are shown but not editable on the new/edit forms.
+	ReadOnlyFields []string
+	// HiddenFields are left off the new/edit forms entirely.
+	HiddenFields []string
 }
 
 // NewResource creates a new admin resource for a model type.
@@ -65,6 +94,24 @@ func (r Resource) WithDisplayFields(fields ...string) Resource {
 	return r
 }
 
+// WithReadOnlyFields shows fields on the forms without letting them be edited.
+func (r Resource) WithReadOnlyFields(fields ...string) Resource {
+	r.ReadOnlyFields = fields
+	return r
+}
+
+// WithHiddenFields leaves fields off the new/edit forms.
+func (r Resource) WithHiddenFields(fields ...string) Resource {
+	r.HiddenFields = fields
+	return r
+}
+
+// WithPerPage sets how many records the index shows per page.
+func (r Resource) WithPerPage(n int) Resource {
+	r.PerPage = n
+	return r
+}
+
 // ReadOnly marks the resource as read-only.
 func (r Resource) ReadOnly() Resource {
 	r.ReadOnlyMode = true
@@ -76,6 +123,11 @@ func Panel(cfg Config) http.Handler {
 	a := &adminPanel{
 		config: cfg,
 		models: make(map[string]Resource),
+		db:     cfg.DB,
+		layout: defaultLayout,
+	}
+	if cfg.Layout != "" {
+		a.layout = template.Must(template.New("layout").Parse(cfg.Layout))
 	}
 	for _, m := range cfg.Models {
 		a.models[strings.ToLower(m.ModelName)] = m
@@ -98,49 +150,71 @@ func Panel(cfg Config) http.Handler {
 	return r
 }
 
-// BasicAuth returns a middleware for HTTP Basic Authentication.
+// BasicAuth returns a middleware for HTTP Basic Authentication. It is
+// framework.BasicAuth, the same check that guards the metrics endpoint.
 func BasicAuth(username, password string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			u, p, ok := r.BasicAuth()
-			if !ok || u != username || p != password {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Gails Admin"`)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-			next.ServeHTTP(w, r)
-		})
-	}
+	return framework.BasicAuth(username, password)
 }
 
+// defaultPerPage is the index page size when a Resource doesn't set one.
+const defaultPerPage = 25
+
 type adminPanel struct {
-	config Config
-	models map[string]Resource
-	db     *gorm.DB
+	config  Config
+	models  map[string]Resource
+	db      *gorm.DB
+	schemas sync.Map
+	layout  *template.Template
 }
 
-func (a *adminPanel) render(w http.ResponseWriter, title, body string) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	// Build sidebar links
-	var sidebar strings.Builder
-	for name := range a.models {
-		sidebar.WriteString(fmt.Sprintf(`<a href="/admin/%s" class="nav-link">%s</a>`, name, strings.Title(name)))
+// names returns the registered model names (as used in URLs), sorted.
+func (a *adminPanel) names() []string {
+	return slices.Sorted(maps.Keys(a.models))
+}
+
+// render executes the named page template with data and writes it wrapped
+// in the layout. Everything dynamic goes through html/template, so record
+// values are escaped wherever they appear.
+func (a *adminPanel) render(w http.ResponseWriter, status int, title, page string, data any) {
+	var content bytes.Buffer
+	if err := pages.ExecuteTemplate(&content, page, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var nav []navLink
+	for _, name := range a.names() {
+		nav = append(nav, navLink{Name: strings.Title(name), URL: "/admin/" + name})
+	}
+	var buf bytes.Buffer
+	err := a.layout.Execute(&buf, layoutData{
+		Title:      title,
+		Nav:        nav,
+		DefaultCSS: template.CSS(defaultCSS),
+		CSS:        template.CSS(a.config.CSS),
+		Content:    template.HTML(content.String()),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	fmt.Fprintf(w, adminLayout, title, sidebar.String(), body)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
 }
 
 func (a *adminPanel) dashboard(w http.ResponseWriter, r *http.Request) {
-	var cards strings.Builder
-	for name, res := range a.models {
-		cards.WriteString(fmt.Sprintf(`
-			<div class="card">
-				<div class="card-title">%s</div>
-				<div class="card-info">%d fields</div>
-				<a href="/admin/%s" class="card-link">View Records →</a>
-			</div>`, strings.Title(res.ModelName), len(res.DisplayFields), name))
+	var cards []dashboardCard
+	for _, name := range a.names() {
+		res := a.models[name]
+		cards = append(cards, dashboardCard{
+			Title:  strings.Title(res.ModelName),
+			Fields: len(res.DisplayFields),
+			URL:    "/admin/" + name,
+		})
 	}
-	a.render(w, "Dashboard", fmt.Sprintf(`<h2>Dashboard</h2><div class="card-grid">%s</div>`, cards.String()))
+	a.render(w, http.StatusOK, "Dashboard", "dashboard", cards)
 }
 
 func (a *adminPanel) index(w http.ResponseWriter, r *http.Request) {
@@ -151,48 +225,128 @@ func (a *adminPanel) index(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	search := r.URL.Query().Get("q")
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	params := listParamsFrom(r, res)
+	page := indexPage{
+		Title:    strings.Title(res.ModelName),
+		URL:      "/admin/" + modelName,
+		ReadOnly: res.ReadOnlyMode,
+		Colspan:  len(res.DisplayFields) + 1,
+	}
+	if len(res.SearchFields) > 0 {
+		page.Search = &searchBox{
+			Placeholder: "Search " + strings.Join(res.SearchFields, ", ") + "...",
+			Query:       params.search,
+		}
+		if params.sort != "" {
+			page.Search.Sort, page.Search.Dir = params.sort, params.dir()
+		}
 	}
 
-	// Build table header
-	var thead strings.Builder
-	thead.WriteString("<tr>")
+	// Each column header toggles sorting by that field.
 	for _, f := range res.DisplayFields {
-		thead.WriteString(fmt.Sprintf("<th>%s</th>", f))
-	}
-	thead.WriteString("<th>Actions</th></tr>")
-
-	body := fmt.Sprintf(`
-		<div class="toolbar">
-			<h2>%s</h2>
-			<div>
-				<form method="get" class="search-form">
-					<input type="text" name="q" placeholder="Search..." value="%s" class="search-input">
-					<button type="submit" class="btn">Search</button>
-				</form>
-				<a href="/admin/%s/export.csv" class="btn btn-secondary">CSV Export</a>
-				%s
-			</div>
-		</div>
-		<table><thead>%s</thead><tbody><tr><td colspan="%d" class="empty">Connect database to view records</td></tr></tbody></table>
-		<div class="pagination">Page %d</div>`,
-		strings.Title(res.ModelName),
-		search,
-		modelName,
-		func() string {
+		dir, arrow := "asc", ""
+		if f == params.sort {
+			if params.desc {
+				arrow = " ▼"
+			} else {
+				dir, arrow = "desc", " ▲"
+			}
+		}
+		page.Columns = append(page.Columns, indexColumn{Name: f, URL: params.with(f, dir, 1), Arrow: arrow})
+	}
+
+	var total int64
+	if a.db == nil {
+		page.Empty = "Connect database to view records"
+	} else {
+		records, count, err := a.list(r, res, params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		total = count
+		page.Empty = "No records found"
+		for i := 0; i < records.Len(); i++ {
+			rec := records.Index(i)
+			row := indexRow{ShowURL: page.URL + "/" + url.PathEscape(a.primaryKey(res, rec))}
 			if !res.ReadOnlyMode {
-				return fmt.Sprintf(`<a href="/admin/%s/new" class="btn btn-primary">+ New</a>`, modelName)
+				row.EditURL = row.ShowURL + "/edit"
 			}
-			return ""
-		}(),
-		thead.String(),
-		len(res.DisplayFields)+1,
-		page)
+			for _, f := range res.DisplayFields {
+				row.Cells = append(row.Cells, formatField(rec, f))
+			}
+			page.Rows = append(page.Rows, row)
+		}
+	}
+	page.Pagination = params.pagination(total)
 
-	a.render(w, strings.Title(res.ModelName), body)
+	a.render(w, http.StatusOK, page.Title, "index", page)
+}
+
+// listParams holds the index page's paging, sorting and search state.
+type listParams struct {
+	page    int
+	perPage int
+	sort    string
+	desc    bool
+	search  string
+}
+
+// listParamsFrom reads ?page=&sort=&dir=&q=. The sort field must be one of
+// the resource's DisplayFields, so it can never inject SQL.
+func listParamsFrom(r *http.Request, res Resource) listParams {
+	q := r.URL.Query()
+	p := listParams{perPage: res.PerPage, search: q.Get("q"), desc: q.Get("dir") == "desc"}
+	if p.perPage <= 0 {
+		p.perPage = defaultPerPage
+	}
+	p.page, _ = strconv.Atoi(q.Get("page"))
+	if p.page < 1 {
+		p.page = 1
+	}
+	if sort := q.Get("sort"); slices.Contains(res.DisplayFields, sort) {
+		p.sort = sort
+	}
+	return p
+}
+
+// dir returns the sort direction as used in the query string.
+func (p listParams) dir() string {
+	if p.desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// with returns the query string for these params with sort, dir and page replaced.
+func (p listParams) with(sort, dir string, page int) string {
+	q := url.Values{}
+	if p.search != "" {
+		q.Set("q", p.search)
+	}
+	if sort != "" {
+		q.Set("sort", sort)
+		q.Set("dir", dir)
+	}
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	return "?" + q.Encode()
+}
+
+func (p listParams) pagination(total int64) pagination {
+	pages := int((total + int64(p.perPage) - 1) / int64(p.perPage))
+	if pages < 1 {
+		pages = 1
+	}
+	pg := pagination{Page: p.page, Pages: pages, Total: total}
+	if p.page > 1 {
+		pg.PrevURL = p.with(p.sort, p.dir(), p.page-1)
+	}
+	if p.page < pages {
+		pg.NextURL = p.with(p.sort, p.dir(), p.page+1)
+	}
+	return pg
 }
 
 func (a *adminPanel) show(w http.ResponseWriter, r *http.Request) {
@@ -204,14 +358,33 @@ func (a *adminPanel) show(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var details strings.Builder
+	var rec reflect.Value
+	if a.db != nil {
+		var err error
+		if rec, err = a.find(r, res, id); err != nil {
+			a.findError(w, r, err)
+			return
+		}
+	}
+
+	page := showPage{
+		Title:     fmt.Sprintf("%s #%s", strings.Title(res.ModelName), id),
+		CSRFToken: framework.CSRFToken(r),
+		BackURL:   "/admin/" + modelName,
+	}
 	for _, f := range res.DisplayFields {
-		details.WriteString(fmt.Sprintf(`<div class="detail-row"><span class="detail-label">%s</span><span class="detail-value">—</span></div>`, f))
+		value := "—"
+		if rec.IsValid() {
+			value = formatField(rec, f)
+		}
+		page.Fields = append(page.Fields, detail{Label: f, Value: value})
+	}
+	if rec.IsValid() && !res.ReadOnlyMode {
+		path := "/admin/" + modelName + "/" + url.PathEscape(id)
+		page.EditURL, page.DeleteURL = path+"/edit", path+"/delete"
 	}
 
-	body := fmt.Sprintf(`<h2>%s #%s</h2><div class="details">%s</div><a href="/admin/%s" class="btn">← Back</a>`,
-		strings.Title(res.ModelName), id, details.String(), modelName)
-	a.render(w, fmt.Sprintf("%s #%s", res.ModelName, id), body)
+	a.render(w, http.StatusOK, page.Title, "show", page)
 }
 
 func (a *adminPanel) new(w http.ResponseWriter, r *http.Request) {
@@ -221,31 +394,39 @@ func (a *adminPanel) new(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-
-	var fields strings.Builder
-	for _, f := range res.DisplayFields {
-		if f == "ID" || f == "CreatedAt" || f == "UpdatedAt" {
-			continue
-		}
-		fields.WriteString(fmt.Sprintf(`
-			<div class="form-group">
-				<label>%s</label>
-				<input type="text" name="%s" class="form-input">
-			</div>`, f, f))
+	if res.ReadOnlyMode {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
 	}
-
-	body := fmt.Sprintf(`
-		<h2>New %s</h2>
-		<form method="post" action="/admin/%s">
-			%s
-			<button type="submit" class="btn btn-primary">Create</button>
-		</form>`, strings.Title(res.ModelName), modelName, fields.String())
-	a.render(w, "New "+res.ModelName, body)
+	a.renderForm(w, r, http.StatusOK, res, modelName, "", reflect.New(res.ModelType).Elem(), nil)
 }
 
 func (a *adminPanel) create(w http.ResponseWriter, r *http.Request) {
 	modelName := chi.URLParam(r, "model")
-	http.Redirect(w, r, "/admin/"+modelName, http.StatusFound)
+	res, ok := a.models[modelName]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if res.ReadOnlyMode {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "Admin panel has no database configured", http.StatusServiceUnavailable)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rec := reflect.New(res.ModelType)
+	if errs := a.save(r, res, rec.Elem(), true); errs != nil {
+		a.renderForm(w, r, http.StatusUnprocessableEntity, res, modelName, "", rec.Elem(), errs)
+		return
+	}
+	http.Redirect(w, r, "/admin/"+modelName+"/"+url.PathEscape(a.primaryKey(res, rec.Elem())), http.StatusFound)
 }
 
 func (a *adminPanel) edit(w http.ResponseWriter, r *http.Request) {
@@ -256,35 +437,82 @@ func (a *adminPanel) edit(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	if res.ReadOnlyMode {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
 
-	var fields strings.Builder
-	for _, f := range res.DisplayFields {
-		if f == "ID" || f == "CreatedAt" || f == "UpdatedAt" {
-			continue
+	rec := reflect.New(res.ModelType).Elem()
+	if a.db != nil {
+		var err error
+		if rec, err = a.find(r, res, id); err != nil {
+			a.findError(w, r, err)
+			return
 		}
-		fields.WriteString(fmt.Sprintf(`
-			<div class="form-group">
-				<label>%s</label>
-				<input type="text" name="%s" class="form-input">
-			</div>`, f, f))
 	}
-
-	body := fmt.Sprintf(`
-		<h2>Edit %s #%s</h2>
-		<form method="post" action="/admin/%s/%s">
-			%s
-			<button type="submit" class="btn btn-primary">Update</button>
-		</form>`, strings.Title(res.ModelName), id, modelName, id, fields.String())
-	a.render(w, "Edit "+res.ModelName, body)
+	a.renderForm(w, r, http.StatusOK, res, modelName, id, rec, nil)
 }
 
 func (a *adminPanel) update(w http.ResponseWriter, r *http.Request) {
 	modelName := chi.URLParam(r, "model")
-	http.Redirect(w, r, "/admin/"+modelName, http.StatusFound)
+	id := chi.URLParam(r, "id")
+	res, ok := a.models[modelName]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if res.ReadOnlyMode {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "Admin panel has no database configured", http.StatusServiceUnavailable)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rec, err := a.find(r, res, id)
+	if err != nil {
+		a.findError(w, r, err)
+		return
+	}
+	if errs := a.save(r, res, rec, false); errs != nil {
+		a.renderForm(w, r, http.StatusUnprocessableEntity, res, modelName, id, rec, errs)
+		return
+	}
+	http.Redirect(w, r, "/admin/"+modelName+"/"+url.PathEscape(id), http.StatusFound)
 }
 
 func (a *adminPanel) delete(w http.ResponseWriter, r *http.Request) {
 	modelName := chi.URLParam(r, "model")
+	id := chi.URLParam(r, "id")
+	res, ok := a.models[modelName]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if res.ReadOnlyMode {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "Admin panel has no database configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	rec, err := a.find(r, res, id)
+	if err != nil {
+		a.findError(w, r, err)
+		return
+	}
+	// Models with a DeletedAt field are soft-deleted by GORM.
+	if err := a.db.WithContext(r.Context()).Delete(rec.Addr().Interface()).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	http.Redirect(w, r, "/admin/"+modelName, http.StatusFound)
 }
 
@@ -304,61 +532,345 @@ func (a *adminPanel) exportCSV(w http.ResponseWriter, r *http.Request) {
 
 	// Write header
 	writer.Write(res.DisplayFields)
-	// Data rows would be written here with DB integration
+	if a.db == nil {
+		return
+	}
+
+	// Stream rows in batches so large tables don't have to fit in memory.
+	batch := reflect.New(reflect.SliceOf(res.ModelType))
+	a.db.WithContext(r.Context()).Model(reflect.New(res.ModelType).Interface()).
+		FindInBatches(batch.Interface(), 500, func(tx *gorm.DB, _ int) error {
+			rows := batch.Elem()
+			for i := 0; i < rows.Len(); i++ {
+				row := make([]string, len(res.DisplayFields))
+				for j, f := range res.DisplayFields {
+					row[j] = formatField(rows.Index(i), f)
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// --- Data access ---
+
+// list loads one page of records and the total number of matching records.
+func (a *adminPanel) list(r *http.Request, res Resource, p listParams) (reflect.Value, int64, error) {
+	sch, err := a.schema(res)
+	if err != nil {
+		return reflect.Value{}, 0, err
+	}
+	query := a.db.WithContext(r.Context()).Model(reflect.New(res.ModelType).Interface())
+	if search := searchCondition(sch, res, p.search); search != nil {
+		query = query.Where(search)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return reflect.Value{}, 0, err
+	}
+
+	order := clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}}
+	if f := sch.LookUpField(p.sort); f != nil && f.DBName != "" {
+		order = clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Desc: p.desc}
+	}
+	records := reflect.New(reflect.SliceOf(res.ModelType))
+	err = query.Order(order).Limit(p.perPage).Offset((p.page - 1) * p.perPage).Find(records.Interface()).Error
+	return records.Elem(), total, err
+}
+
+// searchCondition matches q case-insensitively anywhere in any of the
+// resource's SearchFields (see orm.SearchCondition).
+func searchCondition(sch *schema.Schema, res Resource, q string) clause.Expression {
+	var columns []string
+	for _, name := range res.SearchFields {
+		if f := sch.LookUpField(name); f != nil && f.DBName != "" {
+			columns = append(columns, f.DBName)
+		}
+	}
+	return orm.SearchCondition(q, columns...)
+}
+
+// find loads one record by primary key and returns it as an addressable struct value.
+func (a *adminPanel) find(r *http.Request, res Resource, id string) (reflect.Value, error) {
+	rec := reflect.New(res.ModelType)
+	pk, err := a.primaryField(res)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	err = a.db.WithContext(r.Context()).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}).
+		First(rec.Interface()).Error
+	return rec.Elem(), err
+}
+
+func (a *adminPanel) findError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// renderForm renders the new (id == "") or edit form for rec, with errs
+// from a failed save shown above the fields.
+func (a *adminPanel) renderForm(w http.ResponseWriter, r *http.Request, status int, res Resource, modelName, id string, rec reflect.Value, errs map[string][]string) {
+	page := formPage{
+		Title:     "New " + res.ModelName,
+		CSRFToken: framework.CSRFToken(r),
+		Errors:    helpers.ErrorMessages(errs),
+		Action:    "/admin/" + modelName,
+		Submit:    "Create",
+	}
+	if id != "" {
+		page.Title, page.Action, page.Submit = fmt.Sprintf("Edit %s #%s", res.ModelName, id), "/admin/"+modelName+"/"+url.PathEscape(id), "Update"
+	}
+	for _, f := range res.DisplayFields {
+		if f == "ID" || f == "CreatedAt" || f == "UpdatedAt" || slices.Contains(res.HiddenFields, f) {
+			continue
+		}
+		page.Fields = append(page.Fields, formInput(res, rec, f))
+	}
+	a.render(w, status, page.Title, "form", page)
+}
+
+// save copies the posted form into rec, validates it and creates or saves it.
+// It returns field errors (or a "base" error from the database) on failure.
+func (a *adminPanel) save(r *http.Request, res Resource, rec reflect.Value, create bool) map[string][]string {
+	if errs := populate(rec, res, r.PostForm); len(errs) > 0 {
+		return errs
+	}
+	if errs := orm.ValidateFor(i18n.LocaleFrom(r.Context()), rec.Addr().Interface()); len(errs) > 0 {
+		return errs
+	}
+	db := a.db.WithContext(r.Context())
+	var err error
+	if create {
+		err = db.Create(rec.Addr().Interface()).Error
+	} else {
+		err = db.Save(rec.Addr().Interface()).Error
+	}
+	if err != nil {
+		if errs := orm.HandleDBError(err); errs != nil {
+			return errs
+		}
+		return map[string][]string{"base": {err.Error()}}
+	}
+	return nil
+}
+
+// schema returns the model's schema as parsed by GORM.
+func (a *adminPanel) schema(res Resource) (*schema.Schema, error) {
+	return schema.Parse(reflect.New(res.ModelType).Interface(), &a.schemas, a.db.NamingStrategy)
+}
+
+// primaryField returns the model's primary key as parsed by GORM.
+func (a *adminPanel) primaryField(res Resource) (*schema.Field, error) {
+	sch, err := a.schema(res)
+	if err != nil {
+		return nil, err
+	}
+	if sch.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("admin: %s has no primary key", res.ModelName)
+	}
+	return sch.PrioritizedPrimaryField, nil
+}
+
+// primaryKey returns rec's primary key value as a string for URLs.
+func (a *adminPanel) primaryKey(res Resource, rec reflect.Value) string {
+	name := "ID"
+	if pk, err := a.primaryField(res); err == nil {
+		name = pk.Name
+	}
+	return formatField(rec, name)
+}
+
+// --- Reflection helpers ---
+
+// formatField renders a (possibly promoted) field of rec for display.
+func formatField(rec reflect.Value, name string) string {
+	fv := rec.FieldByName(name)
+	if !fv.IsValid() {
+		return ""
+	}
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return ""
+		}
+		fv = fv.Elem()
+	}
+	switch v := fv.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case gorm.DeletedAt:
+		if !v.Valid {
+			return ""
+		}
+		return v.Time.Format("2006-01-02 15:04:05")
+	case fmt.Stringer:
+		return v.String()
+	}
+	switch fv.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(fv.Bytes())
+		}
+		return "…"
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// formInput describes the input for a field based on its Go type: checkboxes
+// for bools, datetime-local for times, number inputs for numbers and a
+// textarea for `gorm:"type:text"` columns.
+func formInput(res Resource, rec reflect.Value, name string) formField {
+	field := formField{
+		Name:     name,
+		Type:     "text",
+		Value:    inputValue(rec, name),
+		Disabled: slices.Contains(res.ReadOnlyFields, name),
+	}
+
+	sf, ok := res.ModelType.FieldByName(name)
+	if !ok {
+		return field
+	}
+	if strings.Contains(strings.ToLower(sf.Tag.Get("gorm")), "type:text") {
+		field.Type = "textarea"
+		return field
+	}
+
+	ft := sf.Type
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	field.Type = helpers.InferInputType(reflect.Zero(ft).Interface())
+	switch field.Type {
+	case "checkbox":
+		field.Checked = field.Value == "true"
+	case "number":
+		field.Step = "1"
+		if ft.Kind() == reflect.Float32 || ft.Kind() == reflect.Float64 {
+			field.Step = "any"
+		}
+	}
+	return field
+}
+
+// inputValue renders a field as an editable form value.
+func inputValue(rec reflect.Value, name string) string {
+	fv := rec.FieldByName(name)
+	for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+	if fv.IsValid() && fv.Type() == reflect.TypeOf(time.Time{}) {
+		if t := fv.Interface().(time.Time); !t.IsZero() {
+			return t.Format("2006-01-02T15:04")
+		}
+		return ""
+	}
+	return formatField(rec, name)
+}
+
+// populate copies posted values for the resource's editable fields into rec,
+// returning a message for each value that doesn't fit its field's type.
+func populate(rec reflect.Value, res Resource, form url.Values) map[string][]string {
+	errs := make(map[string][]string)
+	for _, name := range res.DisplayFields {
+		if name == "ID" || name == "CreatedAt" || name == "UpdatedAt" ||
+			slices.Contains(res.ReadOnlyFields, name) || slices.Contains(res.HiddenFields, name) {
+			continue
+		}
+		values, posted := form[name]
+		if !posted || len(values) == 0 {
+			continue
+		}
+		fv := rec.FieldByName(name)
+		if !fv.IsValid() || !fv.CanSet() {
+			continue
+		}
+		if err := setField(fv, values[len(values)-1]); err != nil {
+			errs[name] = append(errs[name], err.Error())
+		}
+	}
+	return errs
+}
+
+// setField converts a form string to the field's kind.
+func setField(fv reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if fv.Kind() == reflect.Ptr {
+		if s == "" {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setField(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if fv.Type() == reflect.TypeOf(time.Time{}) {
+		if s == "" {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		for _, layout := range []string{"2006-01-02T15:04", time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				fv.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return errors.New("must be a valid date and time")
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		fv.SetBool(s == "on" || s == "true" || s == "1")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			fv.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			fv.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative whole number")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			fv.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		fv.SetFloat(n)
+	}
+	return nil
 }
 
 func (a *adminPanel) respondJSON(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
-
-const adminLayout = `<!DOCTYPE html>
-<html>
-<head>
-	<title>Gails Admin — %s</title>
-	<style>
-		* { margin: 0; padding: 0; box-sizing: border-box; }
-		body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #0f0f23; color: #e0e0e0; display: flex; min-height: 100vh; }
-		.sidebar { width: 220px; background: #1a1a2e; border-right: 1px solid #16213e; padding: 20px 0; flex-shrink: 0; }
-		.sidebar-title { color: #00d2ff; font-size: 18px; font-weight: bold; padding: 0 20px 20px; border-bottom: 1px solid #16213e; }
-		.nav-link { display: block; padding: 10px 20px; color: #b8b8cc; text-decoration: none; font-size: 14px; transition: all 0.2s; }
-		.nav-link:hover { background: #16213e; color: #fff; }
-		.main { flex: 1; padding: 30px; }
-		h2 { color: #fff; margin-bottom: 20px; font-size: 22px; }
-		.card-grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(250px, 1fr)); gap: 20px; }
-		.card { background: #1a1a2e; border: 1px solid #0f3460; border-radius: 8px; padding: 20px; }
-		.card-title { font-size: 18px; font-weight: bold; color: #fff; margin-bottom: 8px; }
-		.card-info { color: #888; font-size: 13px; margin-bottom: 12px; }
-		.card-link { color: #00d2ff; text-decoration: none; font-size: 14px; }
-		table { width: 100%%; border-collapse: collapse; background: #1a1a2e; border-radius: 8px; overflow: hidden; }
-		th { background: #16213e; color: #00d2ff; font-weight: 600; text-transform: uppercase; font-size: 11px; padding: 12px 16px; text-align: left; }
-		td { padding: 10px 16px; border-bottom: 1px solid #16213e; font-size: 14px; }
-		.empty { text-align: center; color: #666; padding: 40px; }
-		.toolbar { display: flex; justify-content: space-between; align-items: center; margin-bottom: 20px; flex-wrap: wrap; gap: 10px; }
-		.toolbar > div { display: flex; gap: 10px; align-items: center; }
-		.btn { display: inline-block; padding: 8px 16px; border-radius: 6px; text-decoration: none; font-size: 13px; cursor: pointer; border: 1px solid #0f3460; background: #16213e; color: #fff; transition: all 0.2s; }
-		.btn:hover { background: #0f3460; }
-		.btn-primary { background: #0066ff; border-color: #0066ff; }
-		.btn-primary:hover { background: #0055dd; }
-		.btn-secondary { background: transparent; }
-		.search-form { display: flex; gap: 8px; }
-		.search-input { padding: 8px 12px; border-radius: 6px; border: 1px solid #0f3460; background: #16213e; color: #fff; font-size: 13px; }
-		.form-group { margin-bottom: 16px; }
-		.form-group label { display: block; margin-bottom: 6px; font-size: 13px; color: #b8b8cc; }
-		.form-input { width: 100%%; padding: 10px 12px; border-radius: 6px; border: 1px solid #0f3460; background: #16213e; color: #fff; font-size: 14px; }
-		.details { background: #1a1a2e; border-radius: 8px; padding: 20px; margin-bottom: 20px; }
-		.detail-row { display: flex; padding: 10px 0; border-bottom: 1px solid #16213e; }
-		.detail-label { width: 200px; color: #888; font-size: 13px; }
-		.detail-value { color: #fff; font-size: 14px; }
-		.pagination { text-align: center; padding: 20px; color: #888; font-size: 13px; }
-	</style>
-</head>
-<body>
-	<div class="sidebar">
-		<div class="sidebar-title">⚡ Gails Admin</div>
-		<a href="/admin" class="nav-link">Dashboard</a>
-		%s
-	</div>
-	<div class="main">%s</div>
-</body>
-</html>`
@@ -0,0 +1,257 @@
+package admin
+
+import "html/template"
+
+// layoutData is what the layout template is executed with. A custom
+// Config.Layout can use the same fields.
+type layoutData struct {
+	Title string
+	Nav   []navLink
+	// DefaultCSS is the panel's built-in stylesheet, CSS is Config.CSS.
+	DefaultCSS template.CSS
+	CSS        template.CSS
+	// Content is the rendered page.
+	Content template.HTML
+}
+
+type navLink struct {
+	Name string
+	URL  string
+}
+
+type dashboardCard struct {
+	Title  string
+	Fields int
+	URL    string
+}
+
+type indexPage struct {
+	Title    string
+	URL      string
+	ReadOnly bool
+	Search   *searchBox
+	Columns  []indexColumn
+	Rows     []indexRow
+	// Empty is shown instead of rows when there are none.
+	Empty      string
+	Colspan    int
+	Pagination pagination
+}
+
+// searchBox keeps the current sort when searching; paging restarts.
+type searchBox struct {
+	Placeholder string
+	Query       string
+	Sort        string
+	Dir         string
+}
+
+type indexColumn struct {
+	Name  string
+	URL   string
+	Arrow string
+}
+
+type indexRow struct {
+	Cells   []string
+	ShowURL string
+	EditURL string
+}
+
+type pagination struct {
+	Page    int
+	Pages   int
+	Total   int64
+	PrevURL string
+	NextURL string
+}
+
+type showPage struct {
+	Title     string
+	CSRFToken string
+	Fields    []detail
+	BackURL   string
+	EditURL   string
+	DeleteURL string
+}
+
+type detail struct {
+	Label string
+	Value string
+}
+
+type formPage struct {
+	Title     string
+	CSRFToken string
+	Errors    template.HTML
+	Action    string
+	Fields    []formField
+	Submit    string
+}
+
+// formField is one input on the new/edit form. Type is an <input> type, or
+// "textarea".
+type formField struct {
+	Name     string
+	Type     string
+	Value    string
+	Step     string
+	Checked  bool
+	Disabled bool
+}
+
+// pages holds the body of each admin page; render wraps it in the layout.
+var pages = template.Must(template.New("admin").Parse(`
+{{define "dashboard"}}<h2>Dashboard</h2>
+<div class="card-grid">
+	{{- range .}}
+	<div class="card">
+		<div class="card-title">{{.Title}}</div>
+		<div class="card-info">{{.Fields}} fields</div>
+		<a href="{{.URL}}" class="card-link">View Records →</a>
+	</div>
+	{{- end}}
+</div>{{end}}
+
+{{define "index"}}<div class="toolbar">
+	<h2>{{.Title}}</h2>
+	<div>
+		{{- with .Search}}
+		<form method="get" class="search-form">
+			<input type="text" name="q" placeholder="{{.Placeholder}}" value="{{.Query}}" class="search-input">
+			{{- if .Sort}}
+			<input type="hidden" name="sort" value="{{.Sort}}"><input type="hidden" name="dir" value="{{.Dir}}">
+			{{- end}}
+			<button type="submit" class="btn">Search</button>
+		</form>
+		{{- end}}
+		<a href="{{.URL}}/export.csv" class="btn btn-secondary">CSV Export</a>
+		{{- if not .ReadOnly}}
+		<a href="{{.URL}}/new" class="btn btn-primary">+ New</a>
+		{{- end}}
+	</div>
+</div>
+<table>
+	<thead><tr>{{range .Columns}}<th><a href="{{.URL}}">{{.Name}}{{.Arrow}}</a></th>{{end}}<th>Actions</th></tr></thead>
+	<tbody>
+	{{- range .Rows}}
+		<tr>{{range .Cells}}<td>{{.}}</td>{{end}}<td class="actions"><a href="{{.ShowURL}}">View</a>{{with .EditURL}} <a href="{{.}}">Edit</a>{{end}}</td></tr>
+	{{- else}}
+		<tr><td colspan="{{.Colspan}}" class="empty">{{.Empty}}</td></tr>
+	{{- end}}
+	</tbody>
+</table>
+{{with .Pagination}}<div class="pagination">
+	{{- with .PrevURL}}<a href="{{.}}" class="btn">← Prev</a> {{end -}}
+	Page {{.Page}} of {{.Pages}} · {{.Total}} records
+	{{- with .NextURL}} <a href="{{.}}" class="btn">Next →</a>{{end -}}
+</div>{{end}}{{end}}
+
+{{define "show"}}<h2>{{.Title}}</h2>
+<div class="details">
+	{{- range .Fields}}
+	<div class="detail-row"><span class="detail-label">{{.Label}}</span><span class="detail-value">{{.Value}}</span></div>
+	{{- end}}
+</div>
+<a href="{{.BackURL}}" class="btn">← Back</a>
+{{- with .EditURL}}
+<a href="{{.}}" class="btn btn-primary">Edit</a>
+{{- end}}
+{{- with .DeleteURL}}
+<form method="post" action="{{.}}" class="inline-form" onsubmit="return confirm('Delete this record?')">
+	{{- with $.CSRFToken}}
+	<input type="hidden" name="csrf_token" value="{{.}}">
+	{{- end}}
+	<button type="submit" class="btn btn-danger">Delete</button>
+</form>
+{{- end}}{{end}}
+
+{{define "form"}}<h2>{{.Title}}</h2>
+{{.Errors}}
+<form method="post" action="{{.Action}}">
+	{{- with .CSRFToken}}
+	<input type="hidden" name="csrf_token" value="{{.}}">
+	{{- end}}
+	{{- range .Fields}}
+	<div class="form-group">
+		<label>{{.Name}}</label>
+		{{template "input" .}}
+	</div>
+	{{- end}}
+	<button type="submit" class="btn btn-primary">{{.Submit}}</button>
+</form>{{end}}
+
+{{define "input"}}
+{{- if eq .Type "textarea"}}<textarea name="{{.Name}}" rows="6" class="form-input"{{if .Disabled}} disabled{{end}}>{{.Value}}</textarea>
+{{- else if eq .Type "checkbox"}}
+{{- /* The hidden input submits "false" when the box is unticked; populate takes the last posted value, so a ticked box wins. */ -}}
+<input type="hidden" name="{{.Name}}" value="false"{{if .Disabled}} disabled{{end}}><input type="checkbox" name="{{.Name}}" value="true" class="form-checkbox"{{if .Checked}} checked{{end}}{{if .Disabled}} disabled{{end}}>
+{{- else}}<input type="{{.Type}}"{{with .Step}} step="{{.}}"{{end}} name="{{.Name}}" value="{{.Value}}" class="form-input"{{if .Disabled}} disabled{{end}}>
+{{- end}}{{end}}
+`))
+
+// defaultLayout is used unless Config.Layout is set.
+var defaultLayout = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<title>Gails Admin — {{.Title}}</title>
+	<style>{{.DefaultCSS}}</style>
+	{{- with .CSS}}
+	<style>{{.}}</style>
+	{{- end}}
+</head>
+<body>
+	<div class="sidebar">
+		<div class="sidebar-title">⚡ Gails Admin</div>
+		<a href="/admin" class="nav-link">Dashboard</a>
+		{{- range .Nav}}
+		<a href="{{.URL}}" class="nav-link">{{.Name}}</a>
+		{{- end}}
+	</div>
+	<div class="main">{{.Content}}</div>
+</body>
+</html>`))
+
+const defaultCSS = `
+		* { margin: 0; padding: 0; box-sizing: border-box; }
+		body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #0f0f23; color: #e0e0e0; display: flex; min-height: 100vh; }
+		.sidebar { width: 220px; background: #1a1a2e; border-right: 1px solid #16213e; padding: 20px 0; flex-shrink: 0; }
+		.sidebar-title { color: #00d2ff; font-size: 18px; font-weight: bold; padding: 0 20px 20px; border-bottom: 1px solid #16213e; }
+		.nav-link { display: block; padding: 10px 20px; color: #b8b8cc; text-decoration: none; font-size: 14px; transition: all 0.2s; }
+		.nav-link:hover { background: #16213e; color: #fff; }
+		.main { flex: 1; padding: 30px; }
+		h2 { color: #fff; margin-bottom: 20px; font-size: 22px; }
+		.card-grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(250px, 1fr)); gap: 20px; }
+		.card { background: #1a1a2e; border: 1px solid #0f3460; border-radius: 8px; padding: 20px; }
+		.card-title { font-size: 18px; font-weight: bold; color: #fff; margin-bottom: 8px; }
+		.card-info { color: #888; font-size: 13px; margin-bottom: 12px; }
+		.card-link { color: #00d2ff; text-decoration: none; font-size: 14px; }
+		table { width: 100%; border-collapse: collapse; background: #1a1a2e; border-radius: 8px; overflow: hidden; }
+		th a { color: inherit; text-decoration: none; }
+		th { background: #16213e; color: #00d2ff; font-weight: 600; text-transform: uppercase; font-size: 11px; padding: 12px 16px; text-align: left; }
+		td { padding: 10px 16px; border-bottom: 1px solid #16213e; font-size: 14px; }
+		.empty { text-align: center; color: #666; padding: 40px; }
+		.toolbar { display: flex; justify-content: space-between; align-items: center; margin-bottom: 20px; flex-wrap: wrap; gap: 10px; }
+		.toolbar > div { display: flex; gap: 10px; align-items: center; }
+		.btn { display: inline-block; padding: 8px 16px; border-radius: 6px; text-decoration: none; font-size: 13px; cursor: pointer; border: 1px solid #0f3460; background: #16213e; color: #fff; transition: all 0.2s; }
+		.btn:hover { background: #0f3460; }
+		.btn-primary { background: #0066ff; border-color: #0066ff; }
+		.btn-primary:hover { background: #0055dd; }
+		.btn-secondary { background: transparent; }
+		.search-form { display: flex; gap: 8px; }
+		.search-input { padding: 8px 12px; border-radius: 6px; border: 1px solid #0f3460; background: #16213e; color: #fff; font-size: 13px; }
+		.form-group { margin-bottom: 16px; }
+		.form-group label { display: block; margin-bottom: 6px; font-size: 13px; color: #b8b8cc; }
+		.form-checkbox { width: 18px; height: 18px; }
+		textarea.form-input { font-family: inherit; resize: vertical; }
+		.form-input { width: 100%; padding: 10px 12px; border-radius: 6px; border: 1px solid #0f3460; background: #16213e; color: #fff; font-size: 14px; }
+		.details { background: #1a1a2e; border-radius: 8px; padding: 20px; margin-bottom: 20px; }
+		.detail-row { display: flex; padding: 10px 0; border-bottom: 1px solid #16213e; }
+		.detail-label { width: 200px; color: #888; font-size: 13px; }
+		.detail-value { color: #fff; font-size: 14px; }
+		.actions a { color: #00d2ff; text-decoration: none; margin-right: 8px; }
+		.inline-form { display: inline; }
+		.btn-danger { background: #b3261e; border-color: #b3261e; }
+		.pagination { text-align: center; padding: 20px; color: #888; font-size: 13px; }
+		.alert-danger { background: #3b1518; border: 1px solid #b3261e; border-radius: 6px; padding: 12px 16px 12px 32px; margin-bottom: 16px; }
+`
@@ -1,20 +1,50 @@
 package healthcheck
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/shaurya/gails/framework"
+	"go.uber.org/zap"
 )
 
 var bootTime = time.Now()
 
+// defaultCheckTimeout bounds each readiness check.
+const defaultCheckTimeout = 3 * time.Second
+
+// Check is a named readiness check, reported in /health/ready's checks map.
+// It should return promptly once ctx is done.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
 // Plugin provides health check endpoints.
 type Plugin struct {
+	// Checks run on /health/ready alongside the database and Redis pings.
+	Checks []Check
+	// Timeout bounds each check (default 3s); a check that overruns is
+	// reported as "timeout".
+	Timeout time.Duration
+
 	app *framework.App
 }
 
+// New creates the plugin with extra readiness checks:
+//
+//	app.Register(healthcheck.New(
+//		healthcheck.Check{Name: "payments", Run: func(ctx context.Context) error {
+//			return payments.Ping(ctx)
+//		}},
+//	))
+func New(checks ...Check) *Plugin {
+	return &Plugin{Checks: checks}
+}
+
 func (p *Plugin) Name() string    { return "healthcheck" }
 func (p *Plugin) Version() string { return "1.0.0" }
 
@@ -63,26 +93,11 @@ func (p *Plugin) healthHandler(ctx *framework.Context) error {
 }
 
 func (p *Plugin) readyHandler(ctx *framework.Context) error {
+	checks := p.runChecks(ctx.Request.Context())
 	ready := true
-	checks := make(map[string]string)
-
-	if p.app != nil && p.app.DB != nil {
-		if sqlDB, err := p.app.DB.DB(); err == nil {
-			if err := sqlDB.Ping(); err != nil {
-				ready = false
-				checks["db"] = "error"
-			} else {
-				checks["db"] = "ok"
-			}
-		}
-	}
-
-	if p.app != nil && p.app.Redis != nil {
-		if err := p.app.Redis.Ping(ctx.Request.Context()).Err(); err != nil {
+	for _, result := range checks {
+		if result != "ok" {
 			ready = false
-			checks["redis"] = "error"
-		} else {
-			checks["redis"] = "ok"
 		}
 	}
 
@@ -102,3 +117,72 @@ func (p *Plugin) readyHandler(ctx *framework.Context) error {
 	ctx.Response.WriteHeader(status)
 	return json.NewEncoder(ctx.Response).Encode(data)
 }
+
+// readinessChecks returns the built-in database and Redis checks (when
+// configured) followed by the custom ones.
+func (p *Plugin) readinessChecks() []Check {
+	var checks []Check
+	if p.app != nil && p.app.DB != nil {
+		checks = append(checks, Check{Name: "db", Run: func(ctx context.Context) error {
+			sqlDB, err := p.app.DB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}})
+	}
+	if p.app != nil && p.app.Redis != nil {
+		checks = append(checks, Check{Name: "redis", Run: func(ctx context.Context) error {
+			return p.app.Redis.Ping(ctx).Err()
+		}})
+	}
+	return append(checks, p.Checks...)
+}
+
+// runChecks runs every check concurrently, each under its own timeout, and
+// returns "ok", "error" or "timeout" per check.
+func (p *Plugin) runChecks(ctx context.Context) map[string]string {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+
+	checks := p.readinessChecks()
+	results := make(map[string]string, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := runCheck(ctx, check, timeout)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+	return results
+}
+
+func runCheck(parent context.Context, check Check, timeout time.Duration) string {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	// Run in its own goroutine so a check that ignores ctx can't hang the
+	// endpoint past the timeout.
+	done := make(chan error, 1)
+	go func() { done <- check.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			framework.Log.Warn("Health check failed", zap.String("check", check.Name), zap.Error(err))
+			return "error"
+		}
+		return "ok"
+	case <-ctx.Done():
+		framework.Log.Warn("Health check timed out", zap.String("check", check.Name), zap.Duration("timeout", timeout))
+		return "timeout"
+	}
+}
@@ -2,13 +2,25 @@ package requestlog
 
 import (
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/shaurya/gails/framework"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultBufferSize    = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultWorkers       = 2
+)
+
 // RequestLog is the database model for storing request logs.
 type RequestLog struct {
 	ID        uint   `gorm:"primarykey"`
@@ -23,9 +35,27 @@ type RequestLog struct {
 }
 
 // Plugin stores every HTTP request in a request_logs Postgres table.
+//
+// Logs are queued in a bounded buffer and batch-inserted by a few writer
+// goroutines, so a slow database never holds up requests. When the buffer is
+// full, logs are dropped and counted (see Dropped).
 type Plugin struct {
-	app *framework.App
-	db  *gorm.DB
+	// BufferSize is how many logs can wait to be written (default 1024).
+	BufferSize int
+	// BatchSize is the most logs written in one INSERT (default 100).
+	BatchSize int
+	// FlushInterval is how long a partial batch may wait (default 1s).
+	FlushInterval time.Duration
+	// Workers is the number of writer goroutines (default 2).
+	Workers int
+
+	app     *framework.App
+	db      *gorm.DB
+	logs    chan RequestLog
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+	mu      sync.RWMutex // guards closed against sends during Close
+	closed  bool
 }
 
 func (p *Plugin) Name() string    { return "requestlog" }
@@ -36,13 +66,111 @@ func (p *Plugin) Boot(app *framework.App) error {
 	p.db = app.DB
 	if p.db != nil {
 		p.db.AutoMigrate(&RequestLog{})
+		p.start()
 	}
 	return nil
 }
 
+// Shutdown writes the logs still queued when the app stops.
+func (p *Plugin) Shutdown(app *framework.App) error {
+	p.Close()
+	return nil
+}
+
+// Dropped returns how many logs were discarded because the buffer was full.
+func (p *Plugin) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// Close stops accepting logs and waits for the queued ones to be written.
+func (p *Plugin) Close() {
+	p.mu.Lock()
+	if p.logs == nil || p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.logs)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// start creates the buffer and launches the writers.
+func (p *Plugin) start() {
+	if p.BufferSize <= 0 {
+		p.BufferSize = defaultBufferSize
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = defaultBatchSize
+	}
+	if p.FlushInterval <= 0 {
+		p.FlushInterval = defaultFlushInterval
+	}
+	if p.Workers <= 0 {
+		p.Workers = defaultWorkers
+	}
+	p.logs = make(chan RequestLog, p.BufferSize)
+	for i := 0; i < p.Workers; i++ {
+		p.wg.Add(1)
+		go p.writer()
+	}
+}
+
+// writer inserts queued logs whenever a batch fills up or FlushInterval
+// passes, and flushes what's left once the buffer is closed.
+func (p *Plugin) writer() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]RequestLog, 0, p.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.db.CreateInBatches(batch, len(batch)).Error; err != nil {
+			framework.Log.Error("requestlog: insert failed", zap.Int("logs", len(batch)), zap.Error(err))
+		}
+		batch = make([]RequestLog, 0, p.BatchSize)
+	}
+
+	for {
+		select {
+		case log, ok := <-p.logs:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) >= p.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// enqueue queues log without blocking, dropping it if the buffer is full.
+func (p *Plugin) enqueue(log RequestLog) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Add(1)
+		return
+	}
+	select {
+	case p.logs <- log:
+	default:
+		if p.dropped.Add(1)%1000 == 1 {
+			framework.Log.Warn("requestlog: buffer full, dropping logs", zap.Int64("dropped", p.dropped.Load()))
+		}
+	}
+}
+
 func (p *Plugin) Routes(r *framework.Router) {
 	// This plugin adds middleware, not routes
-	if p.db != nil {
+	if p.logs != nil {
 		r.Use(p.logMiddleware())
 	}
 }
@@ -54,25 +182,30 @@ func (p *Plugin) logMiddleware() func(http.Handler) http.Handler {
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 			next.ServeHTTP(ww, r)
 
-			go func() {
-				if p.db == nil {
-					return
-				}
-				log := RequestLog{
-					Method:    r.Method,
-					Path:      r.URL.Path,
-					Status:    ww.Status(),
-					Duration:  float64(time.Since(start).Microseconds()) / 1000.0,
-					IP:        r.RemoteAddr,
-					UserAgent: r.UserAgent(),
-					CreatedAt: time.Now(),
-				}
-				p.db.Create(&log)
-			}()
+			// Values longer than their columns would fail the whole batch.
+			p.enqueue(RequestLog{
+				Method:    truncate(r.Method, 10),
+				Path:      truncate(r.URL.Path, 500),
+				Status:    ww.Status(),
+				Duration:  float64(time.Since(start).Microseconds()) / 1000.0,
+				IP:        truncate(r.RemoteAddr, 50),
+				UserAgent: truncate(r.UserAgent(), 500),
+				CreatedAt: time.Now(),
+			})
 		})
 	}
 }
 
+// truncate cuts s to at most n characters, the unit of a varchar's size.
+// Invalid UTF-8, which Postgres also rejects, is replaced.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
 // GetRouteStats returns per-route hit counts.
 func GetRouteStats(db *gorm.DB) ([]RouteStats, error) {
 	var stats []RouteStats
@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -27,41 +28,30 @@ func NewAsynqAdapter(cfg config.RedisConfig) *AsynqAdapter {
 }
 
 func (a *AsynqAdapter) Enqueue(job Job) error {
-	payload, err := json.Marshal(job)
-	if err != nil {
-		return err
-	}
-	task := asynq.NewTask(fmt.Sprintf("%T", job), payload)
-	_, err = a.Client.Enqueue(task)
-	return err
+	return a.EnqueueContext(context.Background(), job)
 }
 
 func (a *AsynqAdapter) EnqueueIn(delay time.Duration, job Job) error {
-	payload, err := json.Marshal(job)
-	if err != nil {
-		return err
-	}
-	task := asynq.NewTask(fmt.Sprintf("%T", job), payload)
-	_, err = a.Client.Enqueue(task, asynq.ProcessIn(delay))
-	return err
+	return a.EnqueueContext(context.Background(), job, asynq.ProcessIn(delay))
 }
 
 func (a *AsynqAdapter) EnqueueAt(t time.Time, job Job) error {
-	payload, err := json.Marshal(job)
-	if err != nil {
-		return err
-	}
-	task := asynq.NewTask(fmt.Sprintf("%T", job), payload)
-	_, err = a.Client.Enqueue(task, asynq.ProcessAt(t))
-	return err
+	return a.EnqueueContext(context.Background(), job, asynq.ProcessAt(t))
 }
 
 func (a *AsynqAdapter) EnqueueWithQueue(qName string, job Job) error {
+	return a.EnqueueContext(context.Background(), job, asynq.Queue(qName))
+}
+
+// EnqueueContext enqueues job with asynq options. When ctx carries a trace
+// (e.g. ctx.Context() in an action), it travels in the payload, and the
+// job's span links back to the request that enqueued it.
+func (a *AsynqAdapter) EnqueueContext(ctx context.Context, job Job, opts ...asynq.Option) error {
 	payload, err := json.Marshal(job)
 	if err != nil {
 		return err
 	}
-	task := asynq.NewTask(fmt.Sprintf("%T", job), payload)
-	_, err = a.Client.Enqueue(task, asynq.Queue(qName))
+	task := asynq.NewTask(fmt.Sprintf("%T", job), injectTrace(ctx, payload))
+	_, err = a.Client.EnqueueContext(ctx, task, opts...)
 	return err
 }
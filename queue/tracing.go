@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"github.com/shaurya/gails/framework"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceField is the payload field that carries the enqueuing span's trace
+// context (traceparent). Jobs decoding their payload into a struct ignore it.
+const traceField = "_trace"
+
+// injectTrace adds ctx's trace context to a JSON object payload. Other
+// payloads, and contexts without a span, are returned unchanged.
+func injectTrace(ctx context.Context, payload []byte) []byte {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return payload
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil || fields == nil {
+		return payload
+	}
+	trace, err := json.Marshal(carrier)
+	if err != nil {
+		return payload
+	}
+	fields[traceField] = trace
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return out
+}
+
+// startJobSpan starts the span for processing task, linked to the span that
+// enqueued it, if the payload carries one.
+func startJobSpan(ctx context.Context, jobType string, task *asynq.Task) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "asynq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("job.type", jobType),
+		),
+	}
+	var envelope struct {
+		Trace propagation.MapCarrier `json:"_trace"`
+	}
+	if json.Unmarshal(task.Payload(), &envelope) == nil && len(envelope.Trace) > 0 {
+		remote := otel.GetTextMapPropagator().Extract(context.Background(), envelope.Trace)
+		if sc := trace.SpanContextFromContext(remote); sc.IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+		}
+	}
+	return framework.Tracer().Start(ctx, "job "+jobType, opts...)
+}
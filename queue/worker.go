@@ -8,6 +8,7 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/shaurya/gails/config"
 	"github.com/shaurya/gails/framework"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -36,13 +37,13 @@ func NewWorker(redisCfg config.RedisConfig, queueCfg config.QueueConfig) *Worker
 		asynq.Config{
 			Concurrency: concurrency,
 			Queues:      queues,
+			// In-flight jobs get the same drain window as HTTP requests.
+			ShutdownTimeout: framework.DrainTimeout,
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				if framework.Log != nil {
-					framework.Log.Error("Job failed",
-						zap.String("type", task.Type()),
-						zap.Error(err),
-					)
-				}
+				framework.Log.Error("Job failed",
+					zap.String("type", task.Type()),
+					zap.Error(err),
+				)
 				framework.RecordJobProcessed(task.Type(), "failure")
 			}),
 		},
@@ -66,48 +67,69 @@ func (w *Worker) HandleFunc(pattern string, handler func(context.Context, *asynq
 
 // Run starts the worker — this blocks until shutdown signal.
 func (w *Worker) Run() {
-	if framework.Log != nil {
-		framework.Log.Info("Starting Gails worker...")
-	}
+	framework.Log.Info("Starting Gails worker...")
 	if err := w.Server.Run(w.Mux); err != nil {
-		if framework.Log != nil {
-			framework.Log.Fatal("Worker failed", zap.Error(err))
-		}
+		framework.Log.Fatal("Worker failed", zap.Error(err))
+	}
+}
+
+// Start processes jobs in the background, e.g. inside the web process. Pair
+// it with Shutdown so in-flight jobs finish before the app exits:
+//
+//	w.Start()
+//	app.OnShutdown(w.Shutdown)
+func (w *Worker) Start() error {
+	framework.Log.Info("Starting Gails worker...")
+	return w.Server.Start(w.Mux)
+}
+
+// Shutdown stops pulling new jobs and waits for in-flight ones to finish, or
+// for ctx to expire. Jobs that don't finish in time are retried later.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.Server.Stop()
+	done := make(chan struct{})
+	go func() {
+		w.Server.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // loggingHandler wraps a handler with start/completion/failure logging + metrics.
 func loggingHandler(jobType string, next asynq.Handler) asynq.Handler {
 	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		ctx, span := startJobSpan(ctx, jobType, task)
+		defer span.End()
 		start := time.Now()
-		if framework.Log != nil {
-			framework.Log.Info("Job started",
-				zap.String("type", jobType),
-				zap.String("id", task.ResultWriter().TaskID()),
-			)
-		}
+		framework.Log.Info("Job started",
+			zap.String("type", jobType),
+			zap.String("id", task.ResultWriter().TaskID()),
+		)
 
 		err := next.ProcessTask(ctx, task)
 		duration := time.Since(start)
 
 		if err != nil {
-			if framework.Log != nil {
-				framework.Log.Error("Job failed",
-					zap.String("type", jobType),
-					zap.Duration("duration", duration),
-					zap.Error(err),
-				)
-			}
-			framework.RecordJobProcessed(jobType, "failure")
-			return err
-		}
-
-		if framework.Log != nil {
-			framework.Log.Info("Job completed",
+			framework.Log.Error("Job failed",
 				zap.String("type", jobType),
 				zap.Duration("duration", duration),
+				zap.Error(err),
 			)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			framework.RecordJobProcessed(jobType, "failure")
+			return err
 		}
+
+		framework.Log.Info("Job completed",
+			zap.String("type", jobType),
+			zap.Duration("duration", duration),
+		)
 		framework.RecordJobProcessed(jobType, "success")
 		return nil
 	})
@@ -115,32 +137,30 @@ func loggingHandler(jobType string, next asynq.Handler) asynq.Handler {
 
 func loggingHandlerFunc(jobType string, fn func(context.Context, *asynq.Task) error) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, task *asynq.Task) error {
+		ctx, span := startJobSpan(ctx, jobType, task)
+		defer span.End()
 		start := time.Now()
-		if framework.Log != nil {
-			framework.Log.Info("Job started", zap.String("type", jobType))
-		}
+		framework.Log.Info("Job started", zap.String("type", jobType))
 
 		err := fn(ctx, task)
 		duration := time.Since(start)
 
 		if err != nil {
-			if framework.Log != nil {
-				framework.Log.Error("Job failed",
-					zap.String("type", jobType),
-					zap.Duration("duration", duration),
-					zap.Error(err),
-				)
-			}
-			framework.RecordJobProcessed(jobType, "failure")
-			return err
-		}
-
-		if framework.Log != nil {
-			framework.Log.Info("Job completed",
+			framework.Log.Error("Job failed",
 				zap.String("type", jobType),
 				zap.Duration("duration", duration),
+				zap.Error(err),
 			)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			framework.RecordJobProcessed(jobType, "failure")
+			return err
 		}
+
+		framework.Log.Info("Job completed",
+			zap.String("type", jobType),
+			zap.Duration("duration", duration),
+		)
 		framework.RecordJobProcessed(jobType, "success")
 		return nil
 	}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDisk stores files under Root and serves them from URLPrefix. App.Boot
+// mounts it at URLPrefix, so files saved to the default public/uploads are
+// reachable at /uploads/<key>.
+type LocalDisk struct {
+	Root      string
+	URLPrefix string
+}
+
+// NewLocalDisk creates a LocalDisk, defaulting to public/uploads served at /uploads.
+func NewLocalDisk(root, urlPrefix string) *LocalDisk {
+	if root == "" {
+		root = "public/uploads"
+	}
+	if urlPrefix == "" {
+		urlPrefix = "/uploads"
+	}
+	return &LocalDisk{Root: root, URLPrefix: strings.TrimSuffix(urlPrefix, "/")}
+}
+
+// Put writes r to Root/key. The file is written to a temporary name first, so
+// readers never see a partial upload.
+func (d *LocalDisk) Put(key string, r io.Reader) (string, error) {
+	dst, err := d.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+	return d.URL(key), nil
+}
+
+// Get opens Root/key.
+func (d *LocalDisk) Get(key string) (io.ReadCloser, error) {
+	p, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes Root/key.
+func (d *LocalDisk) Delete(key string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL returns URLPrefix/key.
+func (d *LocalDisk) URL(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	return d.URLPrefix + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// ServeHTTP serves stored files; the request path is the key. Directory
+// listings are not served.
+func (d *LocalDisk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p, err := d.path(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if info, err := os.Stat(p); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, p)
+}
+
+func (d *LocalDisk) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d.Root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,54 @@
+// Package storage stores uploaded files behind one interface, on local disk
+// or in S3 (or any S3-compatible service such as MinIO or R2).
+//
+//	fh, _ := ctx.FormFile("avatar")
+//	f, _ := fh.Open()
+//	defer f.Close()
+//	url, err := app.Storage.Put("avatars/"+uuid+".png", f)
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/shaurya/gails/config"
+)
+
+// ErrNotFound is returned by Get for a key that doesn't exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage is a place to keep files, addressed by slash-separated keys.
+type Storage interface {
+	// Put stores r under key, replacing any existing object, and returns its URL.
+	Put(key string, r io.Reader) (url string, err error)
+	// Get opens the object stored under key, or returns ErrNotFound.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the object under key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// URL returns the public URL of the object under key.
+	URL(key string) string
+}
+
+// New builds the storage service named by cfg.Service: "local" (the default)
+// or "s3".
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Service {
+	case "", "local":
+		return NewLocalDisk(cfg.Root, cfg.URLPrefix), nil
+	case "s3":
+		return NewS3(cfg)
+	}
+	return nil, fmt.Errorf("storage: unknown service %q", cfg.Service)
+}
+
+// cleanKey normalizes key and rejects keys that escape the storage root.
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.TrimSpace(key))[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return cleaned, nil
+}
@@ -1,26 +1,43 @@
 package testing
 
 import (
+	"fmt"
 	"reflect"
+	"testing"
 
+	"github.com/shaurya/gails/db"
 	"gorm.io/gorm"
 )
 
 // Factory provides test data creation helpers.
+//
+//	f.Define(&User{}, func(f *testing.Factory) {
+//		f.Set("Name", f.Faker.Name())
+//		f.Set("Email", f.Faker.Email())
+//	})
+//	user := f.Create(&User{Role: "admin"}).(*User) // Role kept, Name/Email generated
 type Factory struct {
+	// Faker generates realistic values inside Define builders.
+	Faker *db.Faker
+
 	db          *gorm.DB
+	t           testing.TB
 	definitions map[string]FactoryDef
+	pending     map[string]any
 }
 
 // FactoryDef holds a factory definition.
 type FactoryDef struct {
+	// Builder is run on every Build, so each instance gets fresh values.
 	Builder func(f *Factory)
-	Fields  map[string]any
+	// Fields are static defaults; values Set by Builder take precedence.
+	Fields map[string]any
 }
 
 // NewFactory creates a new Factory.
 func NewFactory() *Factory {
 	return &Factory{
+		Faker:       db.NewFaker(),
 		definitions: make(map[string]FactoryDef),
 	}
 }
@@ -30,9 +47,14 @@ func (f *Factory) SetDB(db *gorm.DB) {
 	f.db = db
 }
 
+// SetT makes Create fail t when a record can't be saved. NewSuite sets it.
+func (f *Factory) SetT(t testing.TB) {
+	f.t = t
+}
+
 // Define registers a factory definition for a model type.
 func (f *Factory) Define(model any, builder func(f *Factory)) {
-	name := reflect.TypeOf(model).Elem().Name()
+	name := modelName(model)
 	def := FactoryDef{
 		Builder: builder,
 		Fields:  make(map[string]any),
@@ -42,35 +64,87 @@ func (f *Factory) Define(model any, builder func(f *Factory)) {
 
 // Set sets a field value (used inside Define callback).
 func (f *Factory) Set(field string, value any) {
-	// This is used during Build, fields are collected
+	if f.pending != nil {
+		f.pending[field] = value
+	}
 }
 
-// Build creates a model instance WITHOUT persisting to DB.
-// Overrides can be passed as field values on the returned instance.
+// Build fills model's zero-valued fields from its definition WITHOUT
+// persisting to DB. Fields already set on model are treated as overrides and
+// kept.
 func (f *Factory) Build(model any) any {
-	// Return the model as-is for now — in a full implementation,
-	// we'd apply the factory defaults and then override with passed values.
+	def, ok := f.definitions[modelName(model)]
+	if !ok {
+		return model
+	}
+
+	// Builders may Build/Create associated models, so save the outer pending set.
+	outer := f.pending
+	f.pending = make(map[string]any, len(def.Fields))
+	for k, v := range def.Fields {
+		f.pending[k] = v
+	}
+	if def.Builder != nil {
+		def.Builder(f)
+	}
+	values := f.pending
+	f.pending = outer
+
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return model
+	}
+	rv = rv.Elem()
+	for name, value := range values {
+		field := rv.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() || !field.IsZero() || value == nil {
+			continue
+		}
+		val := reflect.ValueOf(value)
+		switch {
+		case val.Type().AssignableTo(field.Type()):
+			field.Set(val)
+		case val.Type().ConvertibleTo(field.Type()):
+			field.Set(val.Convert(field.Type()))
+		}
+	}
 	return model
 }
 
-// Create creates a model instance and persists it to the database.
+// Create builds a model instance and persists it to the database. If the
+// insert fails (e.g. a constraint violation) the test is failed via SetT's t,
+// or Create panics without one, rather than returning an unsaved model.
 func (f *Factory) Create(model any) any {
+	f.Build(model)
 	if f.db != nil {
-		f.db.Create(model)
+		if err := f.db.Create(model).Error; err != nil {
+			if f.t != nil {
+				f.t.Helper()
+				f.t.Fatalf("gails: factory create %s: %v", modelName(model), err)
+			}
+			panic(fmt.Sprintf("gails: factory create %s: %v", modelName(model), err))
+		}
 	}
 	return model
 }
 
-// CreateMany creates N instances and persists them to the database.
+// CreateMany creates N instances and persists them to the database. Fields set
+// on model are copied to every instance; the rest are built per instance.
 func (f *Factory) CreateMany(model any, count int) []any {
 	results := make([]any, count)
 	for i := 0; i < count; i++ {
 		// Create a copy by reflecting
-		v := reflect.New(reflect.TypeOf(model).Elem()).Interface()
-		if f.db != nil {
-			f.db.Create(v)
-		}
-		results[i] = v
+		v := reflect.New(reflect.TypeOf(model).Elem())
+		v.Elem().Set(reflect.ValueOf(model).Elem())
+		results[i] = f.Create(v.Interface())
 	}
 	return results
 }
+
+func modelName(model any) string {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
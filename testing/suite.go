@@ -1,13 +1,18 @@
 package testing
 
 import (
-	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/shaurya/gails/db"
 	"github.com/shaurya/gails/framework"
 	"gorm.io/gorm"
 )
@@ -20,13 +25,33 @@ type Suite struct {
 	Factory *Factory
 	Assert  *Assertions
 	t       *testing.T
+
+	// rootDB is the app's original handle, restored by Close.
+	rootDB *gorm.DB
+	closed bool
 }
 
 // NewSuite creates a new test suite. Call in TestMain or individual tests.
+//
+// When the config has a database, the suite connects to it (once per test
+// binary, through db.Connect) and runs inside a transaction that is rolled
+// back by Close, so tests don't see each other's data. Suite.DB, the factory
+// and App.DB (and so Context.DB in handlers) all use that transaction. A
+// database that can't be reached fails the test.
 func NewSuite(t *testing.T) *Suite {
 	os.Setenv("APP_ENV", "test")
 
 	app := framework.New()
+	if app.DB == nil && app.Config.Database.Host != "" {
+		conn := db.DB
+		if conn == nil {
+			var err error
+			if conn, err = db.Connect(app.Config.Database); err != nil {
+				t.Fatalf("gails: connect test database: %v", err)
+			}
+		}
+		app.DB = conn
+	}
 
 	s := &Suite{
 		App:     app,
@@ -34,64 +59,86 @@ func NewSuite(t *testing.T) *Suite {
 		Factory: NewFactory(),
 		Assert:  &Assertions{t: t},
 		t:       t,
+		rootDB:  app.DB,
+	}
+	if app.DB != nil {
+		tx := app.DB.Begin()
+		if tx.Error != nil {
+			t.Fatalf("gails: begin test transaction: %v", tx.Error)
+		}
+		s.DB = tx
+		app.DB = tx
 	}
+	s.Factory.SetDB(s.DB)
+	s.Factory.SetT(t)
 
 	// Start test HTTP server
-	s.Server = httptest.NewServer(app.Router.Mux)
+	s.Server = httptest.NewServer(app.Router)
+	t.Cleanup(s.Close)
 
 	return s
 }
 
-// Close cleans up the suite.
+// RunInTransaction runs fn inside a savepoint that is rolled back afterwards,
+// isolating changes made by fn from the rest of the test.
+func (s *Suite) RunInTransaction(fn func()) {
+	s.t.Helper()
+	if s.DB == nil {
+		fn()
+		return
+	}
+	name := fmt.Sprintf("gails_test_%d", time.Now().UnixNano())
+	if err := s.DB.SavePoint(name).Error; err != nil {
+		s.t.Fatalf("gails: savepoint: %v", err)
+	}
+	defer func() {
+		if err := s.DB.RollbackTo(name).Error; err != nil {
+			s.t.Errorf("gails: rollback to savepoint: %v", err)
+		}
+	}()
+	fn()
+}
+
+// Close rolls back the suite's transaction and cleans up. It is registered
+// with t.Cleanup, so calling it explicitly is optional.
 func (s *Suite) Close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
 	if s.Server != nil {
 		s.Server.Close()
 	}
+	if s.rootDB != nil && s.DB != s.rootDB {
+		s.DB.Rollback()
+		s.App.DB = s.rootDB
+		s.DB = s.rootDB
+	}
 }
 
 // GET sends a GET request.
 func (s *Suite) GET(path string) *httptest.ResponseRecorder {
-	req, _ := http.NewRequest("GET", path, nil)
-	rr := httptest.NewRecorder()
-	s.App.Router.Mux.ServeHTTP(rr, req)
-	return rr
+	return s.Request("GET", path).Do()
 }
 
 // POST sends a POST request with a JSON body.
 func (s *Suite) POST(path string, body framework.H) *httptest.ResponseRecorder {
-	data, _ := json.Marshal(body)
-	req, _ := http.NewRequest("POST", path, bytes.NewReader(data))
-	req.Header.Set("Content-Type", "application/json")
-	rr := httptest.NewRecorder()
-	s.App.Router.Mux.ServeHTTP(rr, req)
-	return rr
+	return s.Request("POST", path).JSON(body).Do()
 }
 
 // PUT sends a PUT request with a JSON body.
 func (s *Suite) PUT(path string, body framework.H) *httptest.ResponseRecorder {
-	data, _ := json.Marshal(body)
-	req, _ := http.NewRequest("PUT", path, bytes.NewReader(data))
-	req.Header.Set("Content-Type", "application/json")
-	rr := httptest.NewRecorder()
-	s.App.Router.Mux.ServeHTTP(rr, req)
-	return rr
+	return s.Request("PUT", path).JSON(body).Do()
 }
 
 // DELETE sends a DELETE request.
 func (s *Suite) DELETE(path string) *httptest.ResponseRecorder {
-	req, _ := http.NewRequest("DELETE", path, nil)
-	rr := httptest.NewRecorder()
-	s.App.Router.Mux.ServeHTTP(rr, req)
-	return rr
+	return s.Request("DELETE", path).Do()
 }
 
 // GETWithAuth sends a GET request with a Bearer token.
 func (s *Suite) GETWithAuth(path, token string) *httptest.ResponseRecorder {
-	req, _ := http.NewRequest("GET", path, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	rr := httptest.NewRecorder()
-	s.App.Router.Mux.ServeHTTP(rr, req)
-	return rr
+	return s.Request("GET", path).WithToken(token).Do()
 }
 
 // --- Assertions ---
@@ -113,7 +160,7 @@ func (a *Assertions) Equal(expected, actual any) {
 func (a *Assertions) JSONContains(res *httptest.ResponseRecorder, substr string) {
 	a.t.Helper()
 	body := res.Body.String()
-	if !contains(body, substr) {
+	if !strings.Contains(body, substr) {
 		a.t.Errorf("Expected response body to contain %q, got %q", substr, body)
 	}
 }
@@ -122,7 +169,7 @@ func (a *Assertions) JSONContains(res *httptest.ResponseRecorder, substr string)
 func (a *Assertions) HTMLContains(res *httptest.ResponseRecorder, substr string) {
 	a.t.Helper()
 	body := res.Body.String()
-	if !contains(body, substr) {
+	if !strings.Contains(body, substr) {
 		a.t.Errorf("Expected HTML to contain %q", substr)
 	}
 }
@@ -139,15 +186,107 @@ func (a *Assertions) Redirects(res *httptest.ResponseRecorder, url string) {
 	}
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsStr(s, substr))
+// Status asserts the response status code.
+func (a *Assertions) Status(res *httptest.ResponseRecorder, code int) {
+	a.t.Helper()
+	if res.Code != code {
+		a.t.Errorf("Expected status %d, got %d (body: %s)", code, res.Code, truncate(res.Body.String(), 200))
+	}
+}
+
+// Header asserts a response header value.
+func (a *Assertions) Header(res *httptest.ResponseRecorder, key, value string) {
+	a.t.Helper()
+	if got := res.Header().Get(key); got != value {
+		a.t.Errorf("Expected header %s to be %q, got %q", key, value, got)
+	}
 }
 
-func containsStr(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+// JSON asserts the value at a dotted path in the JSON body, e.g.
+// "data.user.email" or "data.users.0.id". Numbers compare by value, so
+// JSON(res, "count", 3) matches a body of {"count": 3}.
+func (a *Assertions) JSON(res *httptest.ResponseRecorder, path string, expected any) {
+	a.t.Helper()
+	actual, ok := a.jsonPath(res, path)
+	if !ok {
+		return
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		a.t.Errorf("Cannot compare %s with %v: %v", path, expected, err)
+		return
+	}
+	if !reflect.DeepEqual(want, actual) {
+		a.t.Errorf("Expected JSON %s to be %v, got %v", path, want, actual)
+	}
+}
+
+// JSONArrayLen asserts the length of the JSON array at path.
+func (a *Assertions) JSONArrayLen(res *httptest.ResponseRecorder, path string, n int) {
+	a.t.Helper()
+	actual, ok := a.jsonPath(res, path)
+	if !ok {
+		return
+	}
+	arr, isArr := actual.([]any)
+	if !isArr {
+		a.t.Errorf("Expected JSON %s to be an array, got %T", path, actual)
+		return
+	}
+	if len(arr) != n {
+		a.t.Errorf("Expected JSON %s to have %d elements, got %d", path, n, len(arr))
+	}
+}
+
+func (a *Assertions) jsonPath(res *httptest.ResponseRecorder, path string) (any, bool) {
+	a.t.Helper()
+	var doc any
+	if err := json.Unmarshal(res.Body.Bytes(), &doc); err != nil {
+		a.t.Errorf("Expected JSON body, got %q: %v", truncate(res.Body.String(), 200), err)
+		return nil, false
+	}
+	if path == "" {
+		return doc, true
+	}
+	cur := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				a.t.Errorf("JSON path %s: key %q not found", path, key)
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				a.t.Errorf("JSON path %s: index %q out of range (len %d)", path, key, len(node))
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			a.t.Errorf("JSON path %s: cannot descend into %T at %q", path, cur, key)
+			return nil, false
 		}
 	}
-	return false
+	return cur, true
+}
+
+// normalizeJSON round-trips v through JSON so it compares equal to decoded values.
+func normalizeJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
 }
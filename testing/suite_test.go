@@ -0,0 +1,49 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type suiteWidget struct {
+	ID   uint
+	Name string
+}
+
+// TestSuiteRollsBack needs a PostgreSQL database, configured through the
+// environment (GAILS_DATABASE_HOST, GAILS_DATABASE_NAME, ...).
+func TestSuiteRollsBack(t *testing.T) {
+	if os.Getenv("GAILS_DATABASE_HOST") == "" && os.Getenv("DATABASE_HOST") == "" {
+		t.Skip("set GAILS_DATABASE_HOST to run against PostgreSQL")
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "app.yaml"), []byte("app:\n  name: suite\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	t.Run("create", func(t *testing.T) {
+		s := NewSuite(t)
+		if s.DB == nil {
+			t.Fatal("Suite.DB is nil")
+		}
+		if err := s.DB.AutoMigrate(&suiteWidget{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DB.Create(&suiteWidget{Name: "first"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("gone", func(t *testing.T) {
+		s := NewSuite(t)
+		if s.DB.Migrator().HasTable(&suiteWidget{}) {
+			var n int64
+			s.DB.Model(&suiteWidget{}).Count(&n)
+			t.Fatalf("table from the previous test survived with %d rows", n)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"time"
 
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
@@ -18,14 +19,18 @@ type Channel interface {
 
 // WSContext wraps a WebSocket connection with room and hub support.
 type WSContext struct {
-	Conn *websocket.Conn
-	Hub  *Hub
-	ctx  context.Context
+	Conn   *websocket.Conn
+	Hub    *Hub
+	ctx    context.Context
+	rooms  map[string]bool // Guarded by Hub.mu
+	userID string          // Guarded by Hub.mu
 }
 
-// Send sends a message to this connection.
+// Send sends a message to this connection, bounded by the hub's write timeout.
 func (c *WSContext) Send(msg any) error {
-	return wsjson.Write(c.ctx, c.Conn, msg)
+	ctx, cancel := context.WithTimeout(c.ctx, c.Hub.WriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, c.Conn, msg)
 }
 
 // JoinRoom joins a named room.
@@ -38,81 +43,318 @@ func (c *WSContext) LeaveRoom(room string) {
 	c.Hub.LeaveRoom(room, c)
 }
 
+// SetUserID associates this connection with a user so it can be targeted by Hub.SendToUser.
+func (c *WSContext) SetUserID(id string) {
+	c.Hub.setUser(c, id)
+}
+
+// UserID returns the user ID associated with this connection, if any.
+func (c *WSContext) UserID() string {
+	c.Hub.mu.RLock()
+	defer c.Hub.mu.RUnlock()
+	return c.userID
+}
+
 // Hub manages WebSocket connections and rooms.
 type Hub struct {
+	// WriteTimeout bounds each individual write so a stalled client can't hold up delivery.
+	WriteTimeout time.Duration
+
+	// OnJoin and OnLeave are called (outside the hub lock) whenever a client
+	// joins or leaves a room, including rooms left implicitly on disconnect.
+	OnJoin  func(room string, ctx *WSContext)
+	OnLeave func(room string, ctx *WSContext)
+
 	mu          sync.RWMutex
 	connections map[*websocket.Conn]bool
 	rooms       map[string]map[*WSContext]bool
+	users       map[string][]*WSContext
+	handlers    sync.WaitGroup // running connection handlers, guarded by mu for Add
+	closing     bool
 }
 
 // NewHub creates a new WebSocket hub.
 func NewHub() *Hub {
 	return &Hub{
-		connections: make(map[*websocket.Conn]bool),
-		rooms:       make(map[string]map[*WSContext]bool),
+		WriteTimeout: 10 * time.Second,
+		connections:  make(map[*websocket.Conn]bool),
+		rooms:        make(map[string]map[*WSContext]bool),
+		users:        make(map[string][]*WSContext),
 	}
 }
 
 // Broadcast sends a message to all connected clients.
 func (h *Hub) Broadcast(msg any) {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	conns := make([]*websocket.Conn, 0, len(h.connections))
 	for conn := range h.connections {
-		wsjson.Write(context.Background(), conn, msg)
+		conns = append(conns, conn)
 	}
+	h.mu.RUnlock()
+
+	h.writeAll(conns, msg)
 }
 
 // BroadcastToRoom sends a message to all clients in a room.
+// Clients whose write fails are removed from all rooms; delivery to the rest continues.
 func (h *Hub) BroadcastToRoom(room string, msg any) {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
-	if conns, ok := h.rooms[room]; ok {
-		for ctx := range conns {
-			ctx.Send(msg)
+	targets := make([]*WSContext, 0, len(h.rooms[room]))
+	for ctx := range h.rooms[room] {
+		targets = append(targets, ctx)
+	}
+	h.mu.RUnlock()
+
+	h.sendAll(targets, msg)
+}
+
+// SendToUser sends a message to every connection associated with a user ID.
+// Connections whose write fails are unregistered; delivery to the rest continues.
+func (h *Hub) SendToUser(userID string, msg any) {
+	h.mu.RLock()
+	targets := append([]*WSContext(nil), h.users[userID]...)
+	h.mu.RUnlock()
+
+	h.sendAll(targets, msg)
+}
+
+// sendAll writes msg to the targets and unregisters any that fail.
+func (h *Hub) sendAll(targets []*WSContext, msg any) {
+	conns := make([]*websocket.Conn, len(targets))
+	for i, ctx := range targets {
+		conns[i] = ctx.Conn
+	}
+	for i, failed := range h.writeAll(conns, msg) {
+		if failed {
+			h.unregister(targets[i])
+		}
+	}
+}
+
+// writeAll writes msg to each connection concurrently, outside the hub lock,
+// and reports which writes failed. It returns once every write has finished
+// or hit WriteTimeout.
+func (h *Hub) writeAll(conns []*websocket.Conn, msg any) []bool {
+	failed := make([]bool, len(conns))
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func(i int, conn *websocket.Conn) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), h.WriteTimeout)
+			defer cancel()
+			failed[i] = wsjson.Write(ctx, conn, msg) != nil
+		}(i, conn)
+	}
+	wg.Wait()
+	return failed
+}
+
+// setUser maps a connection to a user ID, replacing any previous association.
+func (h *Hub) setUser(ctx *WSContext, userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeUser(ctx)
+	ctx.userID = userID
+	if userID != "" {
+		h.users[userID] = append(h.users[userID], ctx)
+	}
+}
+
+// removeUser drops a connection from its user's connection list.
+// Callers must hold h.mu.
+func (h *Hub) removeUser(ctx *WSContext) {
+	if ctx.userID == "" {
+		return
+	}
+	conns := h.users[ctx.userID]
+	for i, c := range conns {
+		if c == ctx {
+			conns = append(conns[:i], conns[i+1:]...)
+			break
 		}
 	}
+	if len(conns) == 0 {
+		delete(h.users, ctx.userID)
+	} else {
+		h.users[ctx.userID] = conns
+	}
+	ctx.userID = ""
 }
 
 // JoinRoom adds a client to a room.
 func (h *Hub) JoinRoom(room string, ctx *WSContext) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 	if h.rooms[room] == nil {
 		h.rooms[room] = make(map[*WSContext]bool)
 	}
+	joined := !h.rooms[room][ctx]
 	h.rooms[room][ctx] = true
+	if ctx.rooms == nil {
+		ctx.rooms = make(map[string]bool)
+	}
+	ctx.rooms[room] = true
+	h.mu.Unlock()
+
+	if joined && h.OnJoin != nil {
+		h.OnJoin(room, ctx)
+	}
 }
 
 // LeaveRoom removes a client from a room.
 func (h *Hub) LeaveRoom(room string, ctx *WSContext) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-	if conns, ok := h.rooms[room]; ok {
-		delete(conns, ctx)
+	left := h.removeFromRoom(room, ctx)
+	h.mu.Unlock()
+
+	if left && h.OnLeave != nil {
+		h.OnLeave(room, ctx)
 	}
 }
 
+// unregister removes a client from every room it has joined and from the user map.
+func (h *Hub) unregister(ctx *WSContext) {
+	h.mu.Lock()
+	var left []string
+	for room := range ctx.rooms {
+		if h.removeFromRoom(room, ctx) {
+			left = append(left, room)
+		}
+	}
+	h.removeUser(ctx)
+	h.mu.Unlock()
+
+	if h.OnLeave != nil {
+		for _, room := range left {
+			h.OnLeave(room, ctx)
+		}
+	}
+}
+
+// removeFromRoom drops a client from a room, deleting the room once empty.
+// It reports whether the client was a member. Callers must hold h.mu.
+func (h *Hub) removeFromRoom(room string, ctx *WSContext) bool {
+	conns, ok := h.rooms[room]
+	if !ok || !conns[ctx] {
+		return false
+	}
+	delete(conns, ctx)
+	if len(conns) == 0 {
+		delete(h.rooms, room)
+	}
+	delete(ctx.rooms, room)
+	return true
+}
+
+// Shutdown closes every connection with StatusGoingAway and waits for their
+// handlers (and OnDisconnect callbacks) to return, or for ctx to expire. New
+// connections are refused with 503 from then on. Register it with the app so
+// it runs during graceful shutdown:
+//
+//	app.OnShutdown(hub.Shutdown)
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	conns := make([]*websocket.Conn, 0, len(h.connections))
+	for conn := range h.connections {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		go conn.Close(websocket.StatusGoingAway, "server shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// accept upgrades the request and registers the connection, unless the hub
+// is shutting down. The caller must call release when the connection ends.
+func (h *Hub) accept(w http.ResponseWriter, r *http.Request) (*websocket.Conn, bool) {
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	h.handlers.Add(1)
+	h.mu.Unlock()
+
+	c, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		h.handlers.Done()
+		return nil, false
+	}
+
+	h.mu.Lock()
+	h.connections[c] = true
+	h.mu.Unlock()
+	return c, true
+}
+
+// release unregisters a connection accepted by accept.
+func (h *Hub) release(c *websocket.Conn) {
+	h.mu.Lock()
+	delete(h.connections, c)
+	h.mu.Unlock()
+	h.handlers.Done()
+}
+
+// ConnectionCount returns the number of open connections.
+func (h *Hub) ConnectionCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.connections)
+}
+
+// RoomCount returns the number of clients in a room.
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// RoomMembers returns the distinct user IDs of clients in a room.
+// Clients without a user ID (see WSContext.SetUserID) are not listed.
+func (h *Hub) RoomMembers(room string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	seen := make(map[string]bool)
+	members := make([]string, 0, len(h.rooms[room]))
+	for ctx := range h.rooms[room] {
+		if ctx.userID != "" && !seen[ctx.userID] {
+			seen[ctx.userID] = true
+			members = append(members, ctx.userID)
+		}
+	}
+	return members
+}
+
 // HandleChannel creates an HTTP handler for a Channel interface.
 func (h *Hub) HandleChannel(ch Channel) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		c, err := websocket.Accept(w, r, nil)
-		if err != nil {
+		c, ok := h.accept(w, r)
+		if !ok {
 			return
 		}
+		defer h.release(c)
 		defer c.Close(websocket.StatusInternalError, "closing")
 
-		h.mu.Lock()
-		h.connections[c] = true
-		h.mu.Unlock()
-
-		defer func() {
-			h.mu.Lock()
-			delete(h.connections, c)
-			h.mu.Unlock()
-		}()
-
 		wsCtx := &WSContext{Conn: c, Hub: h, ctx: r.Context()}
 
+		// Runs after OnDisconnect so channels can still broadcast to their rooms.
+		defer h.unregister(wsCtx)
+
 		if err := ch.OnConnect(wsCtx); err != nil {
 			return
 		}
@@ -133,26 +375,16 @@ func (h *Hub) HandleChannel(ch Channel) http.HandlerFunc {
 
 // Handle provides a simple WebSocket handler without the Channel interface.
 func (h *Hub) Handle(w http.ResponseWriter, r *http.Request) {
-	c, err := websocket.Accept(w, r, nil)
-	if err != nil {
+	c, ok := h.accept(w, r)
+	if !ok {
 		return
 	}
+	defer h.release(c)
 	defer c.Close(websocket.StatusInternalError, "closing")
 
-	h.mu.Lock()
-	h.connections[c] = true
-	h.mu.Unlock()
-
-	defer func() {
-		h.mu.Lock()
-		delete(h.connections, c)
-		h.mu.Unlock()
-	}()
-
 	for {
 		var v interface{}
-		err = wsjson.Read(r.Context(), c, &v)
-		if err != nil {
+		if err := wsjson.Read(r.Context(), c, &v); err != nil {
 			break
 		}
 	}
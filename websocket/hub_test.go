@@ -0,0 +1,78 @@
+package websocket
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+type roomChannel struct{}
+
+func (roomChannel) OnConnect(ctx *WSContext) error {
+	ctx.JoinRoom("lobby")
+	return nil
+}
+
+func (roomChannel) OnMessage(ctx *WSContext, msg []byte) error { return nil }
+
+func (roomChannel) OnDisconnect(ctx *WSContext) error { return nil }
+
+func TestBroadcastToRoomSkipsStalledClient(t *testing.T) {
+	hub := NewHub()
+	hub.WriteTimeout = 3 * time.Second
+	srv := httptest.NewServer(hub.HandleChannel(roomChannel{}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	// The stalled client never reads, so once the socket buffers fill the
+	// hub's write to it blocks until WriteTimeout.
+	stalled, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.CloseNow()
+	fast, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.CloseNow()
+	fast.SetReadLimit(64 << 20)
+
+	for hub.RoomCount("lobby") < 2 {
+		if ctx.Err() != nil {
+			t.Fatal("clients never joined the room")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Larger than any loopback socket buffer.
+	msg := strings.Repeat("x", 32<<20)
+	done := make(chan struct{})
+	go func() {
+		hub.BroadcastToRoom("lobby", msg)
+		close(done)
+	}()
+
+	readCtx, readCancel := context.WithTimeout(ctx, hub.WriteTimeout-time.Second)
+	defer readCancel()
+	var got string
+	if err := wsjson.Read(readCtx, fast, &got); err != nil {
+		t.Fatalf("fast client: %v; delivery waited on the stalled client", err)
+	}
+	if len(got) != len(msg) {
+		t.Errorf("fast client got %d bytes, want %d", len(got), len(msg))
+	}
+
+	<-done
+	if n := hub.RoomCount("lobby"); n != 1 {
+		t.Errorf("RoomCount after broadcast = %d, want 1 (stalled client removed)", n)
+	}
+}